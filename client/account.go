@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"reddittui/client/auth"
+	"reddittui/client/cache"
+	"reddittui/client/common"
+	"reddittui/client/posts"
+	"reddittui/config"
+	"reddittui/model"
+	"reddittui/utils"
+	"strings"
+)
+
+// Writes to the logged in user's account, such as votes. Fullnames identify posts (t3_) and comments (t1_)
+type AccountActions interface {
+	Vote(ctx context.Context, fullname string, vote int) error
+	Save(ctx context.Context, fullname string, saved bool) error
+	Hide(ctx context.Context, fullname string, hidden bool) error
+	Comment(ctx context.Context, parent, text string) (model.Comment, error)
+	Submit(ctx context.Context, subreddit, title, linkUrl, text string) (string, error)
+}
+
+// Reddit account used for the home page and subscriptions when credentials are configured
+type account struct {
+	tokens      *auth.TokenSource
+	apiUrl      string
+	httpClient  *http.Client
+	postsClient posts.RedditPostsClient
+	actions     AccountActions
+}
+
+// Returns nil when there are no credentials or an installed app has not logged in yet
+func newAccount(
+	configuration config.Config,
+	baseUrl string,
+	transport http.RoundTripper,
+	postsCache cache.PostsCache,
+	offline *common.OfflineMode,
+) *account {
+	tokens, credentials, err := newTokenSource(transport)
+	if err != nil {
+		if !errors.Is(err, auth.ErrNotLoggedIn) {
+			slog.Warn("Could not load reddit credentials, using the logged out front page", "error", err)
+		}
+
+		return nil
+	} else if !tokens.IsLoggedIn() {
+		slog.Warn("Run reddittui login to use your reddit account")
+		return nil
+	}
+
+	httpClient := &http.Client{Transport: auth.NewTransport(transport, tokens)}
+	postsClient := posts.NewAccountPostsClient(credentials.ApiUrl, baseUrl, httpClient, postsCache, configuration)
+	postsClient.Offline = offline
+
+	actions := auth.NewApiActions(httpClient, credentials.ApiUrl)
+	return &account{tokens, credentials.ApiUrl, httpClient, postsClient, actions}
+}
+
+// Token source for the credentials in the config directory. Fails with ErrNotLoggedIn without credentials
+func newTokenSource(transport http.RoundTripper) (*auth.TokenSource, auth.Credentials, error) {
+	configDir, err := utils.GetConfigDir()
+	if err != nil {
+		return nil, auth.Credentials{}, err
+	}
+
+	credentialsPath := filepath.Join(configDir, auth.CredentialsFileName)
+	if !auth.HasCredentials(credentialsPath) {
+		return nil, auth.Credentials{}, auth.ErrNotLoggedIn
+	}
+
+	credentials, err := auth.LoadCredentials(credentialsPath)
+	if err != nil {
+		return nil, credentials, err
+	}
+
+	tokenPath := filepath.Join(configDir, auth.TokenFileName)
+	return auth.NewTokenSource(credentials, &http.Client{Transport: transport}, tokenPath), credentials, nil
+}
+
+// Whether the home page and subscriptions come from the user's account
+func (r RedditClient) IsLoggedIn() bool {
+	return r.account != nil
+}
+
+// Subreddits the logged in user is subscribed to
+func (r RedditClient) GetSubscriptions(ctx context.Context) ([]string, error) {
+	if r.account == nil {
+		return nil, auth.ErrNotLoggedIn
+	} else if r.IsOffline() {
+		return nil, common.ErrOffline
+	}
+
+	return auth.GetSubscriptions(ctx, r.account.httpClient, r.account.apiUrl)
+}
+
+// Vote on a post or comment, see model.Upvote and model.Downvote
+func (r RedditClient) Vote(ctx context.Context, fullname string, vote int) error {
+	actions, err := r.accountActions()
+	if err != nil {
+		return err
+	}
+
+	return actions.Vote(ctx, fullname, vote)
+}
+
+func (r RedditClient) Save(ctx context.Context, fullname string, saved bool) error {
+	actions, err := r.accountActions()
+	if err != nil {
+		return err
+	}
+
+	return actions.Save(ctx, fullname, saved)
+}
+
+func (r RedditClient) Hide(ctx context.Context, fullname string, hidden bool) error {
+	actions, err := r.accountActions()
+	if err != nil {
+		return err
+	}
+
+	return actions.Hide(ctx, fullname, hidden)
+}
+
+// Reply to a post or comment, returning the new comment
+func (r RedditClient) Reply(ctx context.Context, parent, text string) (model.Comment, error) {
+	actions, err := r.accountActions()
+	if err != nil {
+		return model.Comment{}, err
+	}
+
+	return actions.Comment(ctx, parent, text)
+}
+
+// Submit a link post when linkUrl is set, otherwise a text post. Returns the url of the new post's comments
+// on the configured server
+func (r RedditClient) Submit(ctx context.Context, subreddit, title, linkUrl, text string) (string, error) {
+	actions, err := r.accountActions()
+	if err != nil {
+		return "", err
+	}
+
+	postUrl, err := actions.Submit(ctx, strings.TrimPrefix(subreddit, "r/"), title, linkUrl, text)
+	if err != nil {
+		return "", err
+	}
+
+	parsed, err := url.Parse(postUrl)
+	if err != nil || len(parsed.Path) == 0 {
+		return postUrl, nil
+	}
+
+	return url.JoinPath(r.BaseUrl, parsed.Path)
+}
+
+func (r RedditClient) accountActions() (AccountActions, error) {
+	if r.account == nil {
+		return nil, auth.ErrNotLoggedIn
+	} else if r.IsOffline() {
+		return nil, common.ErrOffline
+	}
+
+	return r.account.actions, nil
+}
+
+// Home posts come from the account when logged in
+func (r RedditClient) homePostsClient() posts.RedditPostsClient {
+	if r.account != nil {
+		return r.account.postsClient
+	}
+
+	return r.postsClient
+}
+
+// Authorize reddittui to use the account in the credentials file, opening the browser for installed apps
+func Login(ctx context.Context, configuration config.Config, openUrl func(string) error) error {
+	tokens, _, err := newTokenSource(newTransport(configuration))
+	if err != nil {
+		return err
+	}
+
+	return tokens.Login(ctx, openUrl)
+}
+
+// Forget the saved access and refresh tokens
+func Logout(configuration config.Config) error {
+	tokens, _, err := newTokenSource(newTransport(configuration))
+	if err != nil {
+		return err
+	}
+
+	return tokens.Logout()
+}
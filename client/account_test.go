@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reddittui/client/auth"
+	"reddittui/client/common"
+	"reddittui/config"
+	"reddittui/model"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Client logged in with script credentials for a stand-in oauth and api server
+func newTestAccountClient(t *testing.T, mux *http.ServeMux) RedditClient {
+	mux.HandleFunc("/api/v1/access_token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"access_token": "token", "expires_in": 3600}`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	configDir := filepath.Join(home, ".config", "reddittui")
+	os.MkdirAll(configDir, 0750)
+	credentials := fmt.Sprintf(`
+type = "script"
+clientId = "client"
+clientSecret = "secret"
+username = "user"
+password = "hunter2"
+tokenUrl = "%[1]s/api/v1/access_token"
+apiUrl = "%[1]s"
+`, server.URL)
+	os.WriteFile(filepath.Join(configDir, auth.CredentialsFileName), []byte(credentials), 0600)
+
+	configuration := config.NewConfig()
+	configuration.Core.BypassCache = true
+	configuration.Client.Prefetch = false
+	redditClient := NewRedditClient(configuration)
+
+	if !redditClient.IsLoggedIn() {
+		t.Fatalf("expected client to be logged in with script credentials")
+	}
+
+	return redditClient
+}
+
+func TestAccountHomePosts(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {"id": "mine", "title": "subscribed post", "subreddit": "golang", "likes": true, "saved": true}}]}}`)
+	})
+	mux.HandleFunc("/subreddits/mine/subscriber", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": [{"kind": "t5", "data": {"display_name": "golang"}}]}}`)
+	})
+
+	redditClient := newTestAccountClient(t, mux)
+
+	ctx := context.Background()
+	posts, err := redditClient.GetHomePosts(ctx, model.DefaultPostSort(), "")
+	if err != nil {
+		t.Fatalf("unexpected error getting home posts: %v", err)
+	}
+
+	if len(posts.Posts) != 1 || posts.Posts[0].Id != "mine" {
+		t.Fatalf("got %v, want the account's front page", posts.Posts)
+	}
+	if post := posts.Posts[0]; post.Vote != model.Upvote || !post.Saved {
+		t.Errorf("got vote %d and saved %v, want the account's upvote and save", post.Vote, post.Saved)
+	}
+
+	subscriptions, err := redditClient.GetSubscriptions(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error getting subscriptions: %v", err)
+	}
+	if !slices.Equal(subscriptions, []string{"golang"}) {
+		t.Errorf("got subscriptions %v, want [golang]", subscriptions)
+	}
+}
+
+func TestAccountActions(t *testing.T) {
+	var requests []string
+
+	mux := http.NewServeMux()
+	record := func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		requests = append(requests, fmt.Sprintf("%s id=%s dir=%s", r.URL.Path, r.PostForm.Get("id"), r.PostForm.Get("dir")))
+		if r.PostForm.Get("id") == "t3_archived" {
+			fmt.Fprint(w, `{"json": {"errors": [["TOO_OLD", "that's a piece of history now", "id"]]}}`)
+			return
+		}
+
+		fmt.Fprint(w, `{}`)
+	}
+	mux.HandleFunc("/api/vote", record)
+	mux.HandleFunc("/api/save", record)
+	mux.HandleFunc("/api/unsave", record)
+	mux.HandleFunc("/api/hide", record)
+	mux.HandleFunc("/api/unhide", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	})
+
+	mux.HandleFunc("/api/comment", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("thing_id") != "t1_def" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+
+		fmt.Fprintf(w, `{"json": {"errors": [], "data": {"things": [{"kind": "t1", "data": {"id": "new", "author": "user", "body": "%s", "score": 1, "likes": true}}]}}}`, r.PostForm.Get("text"))
+	})
+	mux.HandleFunc("/api/submit", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("sr") != "golang" || r.PostForm.Get("kind") != "link" || r.PostForm.Get("url") != "https://go.dev" {
+			fmt.Fprint(w, `{"json": {"errors": [["BAD_URL", "you should check that url", "url"]]}}`)
+			return
+		}
+
+		fmt.Fprint(w, `{"json": {"errors": [], "data": {"url": "https://www.reddit.com/r/golang/comments/new/go/", "name": "t3_new"}}}`)
+	})
+
+	redditClient := newTestAccountClient(t, mux)
+	ctx := context.Background()
+
+	reply, err := redditClient.Reply(ctx, "t1_def", "well said")
+	if err != nil {
+		t.Fatalf("unexpected error replying: %v", err)
+	}
+	if reply.Id != "new" || reply.Text != "well said" || reply.Points != "1 point" || reply.Vote != model.Upvote {
+		t.Errorf("got reply %+v, want the new comment", reply)
+	}
+
+	commentsUrl, err := redditClient.Submit(ctx, "r/golang", "Go", "https://go.dev", "")
+	if err != nil {
+		t.Fatalf("unexpected error submitting: %v", err)
+	}
+	if want := "https://old.reddit.com/r/golang/comments/new/go/"; commentsUrl != want {
+		t.Errorf("got comments url %s, want %s", commentsUrl, want)
+	}
+
+	if _, err := redditClient.Submit(ctx, "golang", "Go", "", "text"); err == nil || !strings.Contains(err.Error(), "BAD_URL") {
+		t.Errorf("got error %v, want BAD_URL", err)
+	}
+
+	if err := redditClient.Vote(ctx, "t3_abc", model.Upvote); err != nil {
+		t.Errorf("unexpected error voting: %v", err)
+	}
+	if err := redditClient.Vote(ctx, "t1_def", model.NoVote); err != nil {
+		t.Errorf("unexpected error taking back vote: %v", err)
+	}
+	if err := redditClient.Save(ctx, "t3_abc", true); err != nil {
+		t.Errorf("unexpected error saving: %v", err)
+	}
+	if err := redditClient.Save(ctx, "t3_abc", false); err != nil {
+		t.Errorf("unexpected error unsaving: %v", err)
+	}
+	if err := redditClient.Hide(ctx, "t3_abc", true); err != nil {
+		t.Errorf("unexpected error hiding: %v", err)
+	}
+
+	want := []string{
+		"/api/vote id=t3_abc dir=1",
+		"/api/vote id=t1_def dir=0",
+		"/api/save id=t3_abc dir=",
+		"/api/unsave id=t3_abc dir=",
+		"/api/hide id=t3_abc dir=",
+	}
+	if !slices.Equal(requests, want) {
+		t.Errorf("got requests %v, want %v", requests, want)
+	}
+
+	if err := redditClient.Vote(ctx, "t3_archived", model.Downvote); err == nil || !strings.Contains(err.Error(), "TOO_OLD") {
+		t.Errorf("got error %v, want TOO_OLD", err)
+	}
+	if err := redditClient.Hide(ctx, "t3_abc", false); !errors.Is(err, common.ErrForbidden) {
+		t.Errorf("got error %v, want %v", err, common.ErrForbidden)
+	}
+}
+
+func TestLoggedOutActions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	configuration := config.NewConfig()
+	configuration.Core.BypassCache = true
+	redditClient := NewRedditClient(configuration)
+
+	if err := redditClient.Vote(context.Background(), "t3_abc", model.Upvote); !errors.Is(err, auth.ErrNotLoggedIn) {
+		t.Errorf("got error %v, want %v", err, auth.ErrNotLoggedIn)
+	}
+}
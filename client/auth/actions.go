@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reddittui/client/common"
+	"reddittui/model"
+	"reddittui/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Writes to the logged in user's account through the api. Fullnames identify posts (t3_) and comments (t1_)
+type ApiActions struct {
+	Client *http.Client
+	ApiUrl string
+}
+
+func NewApiActions(httpClient *http.Client, apiUrl string) ApiActions {
+	return ApiActions{
+		Client: httpClient,
+		ApiUrl: strings.TrimSuffix(apiUrl, "/"),
+	}
+}
+
+// Upvote with 1, downvote with -1 and take the vote back with 0
+func (a ApiActions) Vote(ctx context.Context, fullname string, vote int) error {
+	form := url.Values{}
+	form.Set("id", fullname)
+	form.Set("dir", strconv.Itoa(vote))
+	return a.post(ctx, "/api/vote", form, nil)
+}
+
+func (a ApiActions) Save(ctx context.Context, fullname string, saved bool) error {
+	path := "/api/save"
+	if !saved {
+		path = "/api/unsave"
+	}
+
+	form := url.Values{}
+	form.Set("id", fullname)
+	return a.post(ctx, path, form, nil)
+}
+
+// Only posts can be hidden
+func (a ApiActions) Hide(ctx context.Context, fullname string, hidden bool) error {
+	path := "/api/hide"
+	if !hidden {
+		path = "/api/unhide"
+	}
+
+	form := url.Values{}
+	form.Set("id", fullname)
+	return a.post(ctx, path, form, nil)
+}
+
+// Reply to a post (t3_) or comment (t1_) with markdown text, returning the new comment
+func (a ApiActions) Comment(ctx context.Context, parent, text string) (model.Comment, error) {
+	form := url.Values{}
+	form.Set("thing_id", parent)
+	form.Set("text", text)
+
+	var data struct {
+		Things []common.Thing `json:"things"`
+	}
+
+	if err := a.post(ctx, "/api/comment", form, &data); err != nil {
+		return model.Comment{}, err
+	}
+
+	for _, thing := range data.Things {
+		var comment common.CommentData
+		if thing.Kind != common.CommentKind {
+			continue
+		} else if err := json.Unmarshal(thing.Data, &comment); err != nil {
+			return model.Comment{}, err
+		}
+
+		created := common.FromUnixTimestamp(comment.CreatedUtc)
+		return model.Comment{
+			Id:        comment.Id,
+			Author:    comment.Author,
+			Text:      strings.TrimSpace(comment.Body),
+			Points:    utils.GetSingularPlural(strconv.Itoa(comment.Score), "point", "points"),
+			Score:     comment.Score,
+			Timestamp: utils.FormatRelativeTime(created, time.Now()),
+			Created:   created,
+			Vote:      common.VoteFromLikes(comment.Likes),
+		}, nil
+	}
+
+	return model.Comment{}, fmt.Errorf("/api/comment did not return the new comment")
+}
+
+// Submit a link post when linkUrl is set, otherwise a text post. Returns the url of the new post
+func (a ApiActions) Submit(ctx context.Context, subreddit, title, linkUrl, text string) (string, error) {
+	form := url.Values{}
+	form.Set("sr", subreddit)
+	form.Set("title", title)
+	if len(linkUrl) > 0 {
+		form.Set("kind", "link")
+		form.Set("url", linkUrl)
+	} else {
+		form.Set("kind", "self")
+		form.Set("text", text)
+	}
+
+	var data struct {
+		Url string `json:"url"`
+	}
+
+	if err := a.post(ctx, "/api/submit", form, &data); err != nil {
+		return "", err
+	}
+
+	return data.Url, nil
+}
+
+// Post the form, decoding the data of the json response into data when it is not nil
+func (a ApiActions) post(ctx context.Context, path string, form url.Values, data any) error {
+	form.Set("api_type", "json")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", a.ApiUrl+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := a.Client.Do(req)
+	if err != nil {
+		return common.WrapRequestError(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return common.NewResponseError(res)
+	}
+
+	// Failures such as voting on archived posts are reported with a 200 and a list of errors
+	var response struct {
+		Json struct {
+			Errors [][]string      `json:"errors"`
+			Data   json.RawMessage `json:"data"`
+		} `json:"json"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		if data == nil {
+			// Votes and saves respond with an empty object
+			return nil
+		}
+
+		return err
+	} else if len(response.Json.Errors) > 0 {
+		return fmt.Errorf("%s failed: %v", path, response.Json.Errors[0])
+	} else if data != nil {
+		if len(response.Json.Data) == 0 {
+			return fmt.Errorf("%s returned no data", path)
+		}
+
+		return json.Unmarshal(response.Json.Data, data)
+	}
+
+	return nil
+}
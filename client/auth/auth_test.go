@@ -0,0 +1,302 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testClientId     = "client"
+	testClientSecret = "secret"
+	testCode         = "authorization-code"
+)
+
+// Stand-in for reddit's oauth and api servers
+type testServer struct {
+	*httptest.Server
+
+	mu             sync.Mutex
+	grants         []string
+	accessTokens   int
+	revokedToken   string
+	authorizations []string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	s := &testServer{}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/api/v1/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("client_id") != testClientId || query.Get("response_type") != "code" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		redirect := fmt.Sprintf("%s?state=%s&code=%s", query.Get("redirect_uri"), query.Get("state"), testCode)
+		http.Redirect(w, r, redirect, http.StatusFound)
+	})
+
+	mux.HandleFunc("/api/v1/access_token", func(w http.ResponseWriter, r *http.Request) {
+		clientId, clientSecret, ok := r.BasicAuth()
+		if !ok || clientId != testClientId || clientSecret != testClientSecret {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		r.ParseForm()
+		grant := r.PostForm.Get("grant_type")
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.grants = append(s.grants, grant)
+
+		switch {
+		case grant == "password" && r.PostForm.Get("password") != "hunter2":
+			// Reddit reports wrong passwords with a 200
+			fmt.Fprint(w, `{"error": "invalid_grant"}`)
+			return
+		case grant == "authorization_code" && r.PostForm.Get("code") != testCode:
+			fmt.Fprint(w, `{"error": "invalid_grant"}`)
+			return
+		case grant == "refresh_token" && r.PostForm.Get("refresh_token") != "refresh":
+			fmt.Fprint(w, `{"error": "invalid_grant"}`)
+			return
+		}
+
+		s.accessTokens++
+		refreshToken := ""
+		if grant == "authorization_code" {
+			refreshToken = "refresh"
+		}
+
+		fmt.Fprintf(w, `{"access_token": "token%d", "refresh_token": "%s", "expires_in": 3600}`, s.accessTokens, refreshToken)
+	})
+
+	mux.HandleFunc("/subreddits/mine/subscriber", func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if r.URL.Query().Get("after") == "" {
+			fmt.Fprint(w, `{"kind": "Listing", "data": {"after": "t5_2", "children": [
+				{"kind": "t5", "data": {"display_name": "golang"}},
+				{"kind": "t5", "data": {"display_name": "AskReddit"}}
+			]}}`)
+			return
+		}
+
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": [{"kind": "t5", "data": {"display_name": "dogs"}}]}}`)
+	})
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testServer) authorized(r *http.Request) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	authorization := r.Header.Get("Authorization")
+	s.authorizations = append(s.authorizations, authorization)
+	return strings.HasPrefix(authorization, "bearer token") && authorization != "bearer "+s.revokedToken
+}
+
+func (s *testServer) credentials(appType string) Credentials {
+	return Credentials{
+		Type:         appType,
+		ClientId:     testClientId,
+		ClientSecret: testClientSecret,
+		Username:     "user",
+		Password:     "hunter2",
+		RedirectUri:  "http://127.0.0.1/callback",
+		AuthorizeUrl: s.URL + "/api/v1/authorize",
+		TokenUrl:     s.URL + "/api/v1/access_token",
+		ApiUrl:       s.URL,
+	}
+}
+
+func TestLoadCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), CredentialsFileName)
+	os.WriteFile(path, []byte("type = \"Installed\"\nclientId = \"abc\"\napiUrl = \"http://localhost:8080/\"\n"), 0600)
+
+	credentials, err := LoadCredentials(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading credentials: %v", err)
+	}
+
+	if credentials.Type != InstalledApp {
+		t.Errorf("got type %s, want %s", credentials.Type, InstalledApp)
+	}
+	if credentials.RedirectUri != defaultRedirectUri {
+		t.Errorf("got redirect uri %s, want %s", credentials.RedirectUri, defaultRedirectUri)
+	}
+	if credentials.TokenUrl != defaultTokenUrl {
+		t.Errorf("got token url %s, want %s", credentials.TokenUrl, defaultTokenUrl)
+	}
+	if credentials.ApiUrl != "http://localhost:8080" {
+		t.Errorf("got api url %s, want http://localhost:8080", credentials.ApiUrl)
+	}
+
+	os.WriteFile(path, []byte("clientId = \"abc\"\n"), 0600)
+	if _, err := LoadCredentials(path); err == nil {
+		t.Errorf("expected script credentials without a password to be invalid")
+	}
+}
+
+func TestScriptToken(t *testing.T) {
+	server := newTestServer(t)
+	tokenPath := filepath.Join(t.TempDir(), TokenFileName)
+	tokens := NewTokenSource(server.credentials(ScriptApp), server.Client(), tokenPath)
+
+	now := time.Now()
+	tokens.now = func() time.Time { return now }
+
+	if !tokens.IsLoggedIn() {
+		t.Errorf("expected script apps to be logged in without a saved token")
+	}
+
+	ctx := context.Background()
+	token, err := tokens.Token(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error getting token: %v", err)
+	}
+	if token != "token1" {
+		t.Errorf("got token %s, want token1", token)
+	}
+
+	// Cached until it is about to expire
+	token, _ = tokens.Token(ctx)
+	if token != "token1" {
+		t.Errorf("got token %s, want cached token1", token)
+	}
+
+	now = now.Add(time.Hour)
+	token, _ = tokens.Token(ctx)
+	if token != "token2" {
+		t.Errorf("got token %s, want token2 after expiry", token)
+	}
+
+	if got := server.grants; !slices.Equal(got, []string{"password", "password"}) {
+		t.Errorf("got grants %v, want two password grants", got)
+	}
+
+	credentials := server.credentials(ScriptApp)
+	credentials.Password = "wrong"
+	tokens = NewTokenSource(credentials, server.Client(), filepath.Join(t.TempDir(), TokenFileName))
+	if _, err := tokens.Token(ctx); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("got error %v, want invalid_grant", err)
+	}
+}
+
+func TestInstalledLogin(t *testing.T) {
+	server := newTestServer(t)
+	tokenPath := filepath.Join(t.TempDir(), TokenFileName)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error listening for the redirect: %v", err)
+	}
+
+	credentials := server.credentials(InstalledApp)
+	credentials.RedirectUri = fmt.Sprintf("http://%s/callback", listener.Addr())
+	tokens := NewTokenSource(credentials, server.Client(), tokenPath)
+
+	ctx := context.Background()
+	if tokens.IsLoggedIn() {
+		t.Errorf("expected installed apps to not be logged in before login")
+	}
+	if _, err := tokens.Token(ctx); err != ErrNotLoggedIn {
+		t.Errorf("got error %v, want %v", err, ErrNotLoggedIn)
+	}
+
+	// Stands in for the browser, following the redirect back to reddittui
+	openUrl := func(authorizeUrl string) error {
+		go func() {
+			res, err := http.Get(authorizeUrl)
+			if err == nil {
+				res.Body.Close()
+			}
+		}()
+
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := tokens.login(ctx, listener, openUrl); err != nil {
+		t.Fatalf("unexpected error logging in: %v", err)
+	}
+	if !tokens.IsLoggedIn() {
+		t.Errorf("expected to be logged in after login")
+	}
+
+	// The refresh token is saved for the next run
+	tokens = NewTokenSource(credentials, server.Client(), tokenPath)
+	if !tokens.IsLoggedIn() {
+		t.Errorf("expected saved refresh token to be loaded")
+	}
+
+	tokens.Invalidate()
+	token, err := tokens.Token(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error refreshing token: %v", err)
+	}
+	if token != "token2" {
+		t.Errorf("got token %s, want token2", token)
+	}
+	if got := server.grants; !slices.Equal(got, []string{"authorization_code", "refresh_token"}) {
+		t.Errorf("got grants %v, want authorization_code then refresh_token", got)
+	}
+
+	if err := tokens.Logout(); err != nil {
+		t.Fatalf("unexpected error logging out: %v", err)
+	}
+	if _, err := os.Stat(tokenPath); !os.IsNotExist(err) {
+		t.Errorf("expected token file to be removed after logout")
+	}
+}
+
+func TestTransportAndSubscriptions(t *testing.T) {
+	server := newTestServer(t)
+	tokens := NewTokenSource(server.credentials(ScriptApp), server.Client(), filepath.Join(t.TempDir(), TokenFileName))
+	httpClient := &http.Client{Transport: NewTransport(server.Client().Transport, tokens)}
+
+	ctx := context.Background()
+	subscriptions, err := GetSubscriptions(ctx, httpClient, server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error getting subscriptions: %v", err)
+	}
+
+	want := []string{"AskReddit", "dogs", "golang"}
+	if !slices.Equal(subscriptions, want) {
+		t.Errorf("got subscriptions %v, want %v", subscriptions, want)
+	}
+
+	// A revoked token is replaced on the next request
+	server.revokedToken = "token1"
+	if _, err := GetSubscriptions(ctx, httpClient, server.URL); err == nil {
+		t.Errorf("expected revoked token to fail")
+	}
+	if _, err := GetSubscriptions(ctx, httpClient, server.URL); err != nil {
+		t.Errorf("unexpected error after token was replaced: %v", err)
+	}
+
+	last := server.authorizations[len(server.authorizations)-1]
+	if last != "bearer token2" {
+		t.Errorf("got authorization %s, want bearer token2", last)
+	}
+}
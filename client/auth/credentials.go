@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	CredentialsFileName = "auth.toml"
+	TokenFileName       = "token.json"
+
+	// Reddit app types, see https://www.reddit.com/prefs/apps
+	ScriptApp    = "script"
+	InstalledApp = "installed"
+
+	defaultAuthorizeUrl = "https://www.reddit.com/api/v1/authorize"
+	defaultTokenUrl     = "https://www.reddit.com/api/v1/access_token"
+	defaultApiUrl       = "https://oauth.reddit.com"
+	defaultRedirectUri  = "http://localhost:65010/callback"
+	scopes              = "identity read mysubreddits vote save report submit"
+)
+
+var (
+	ErrNotLoggedIn = errors.New("not logged in")
+	ErrAuthFailed  = errors.New("authentication failed")
+)
+
+// Reddit app credentials read from the config directory. Script apps log in with the account's username
+// and password, installed apps are authorized once in the browser with "reddittui login". The urls only
+// need to be set to use a server other than reddit
+type Credentials struct {
+	Type         string
+	ClientId     string
+	ClientSecret string
+	Username     string
+	Password     string
+	RedirectUri  string
+	AuthorizeUrl string
+	TokenUrl     string
+	ApiUrl       string
+}
+
+func LoadCredentials(path string) (Credentials, error) {
+	var credentials Credentials
+	if _, err := toml.DecodeFile(path, &credentials); err != nil {
+		return credentials, err
+	}
+
+	credentials.Type = strings.ToLower(strings.TrimSpace(credentials.Type))
+	if len(credentials.Type) == 0 {
+		credentials.Type = ScriptApp
+	}
+	if len(credentials.RedirectUri) == 0 {
+		credentials.RedirectUri = defaultRedirectUri
+	}
+	if len(credentials.AuthorizeUrl) == 0 {
+		credentials.AuthorizeUrl = defaultAuthorizeUrl
+	}
+	if len(credentials.TokenUrl) == 0 {
+		credentials.TokenUrl = defaultTokenUrl
+	}
+	if len(credentials.ApiUrl) == 0 {
+		credentials.ApiUrl = defaultApiUrl
+	}
+	credentials.ApiUrl = strings.TrimSuffix(credentials.ApiUrl, "/")
+
+	return credentials, credentials.Validate()
+}
+
+func (c Credentials) Validate() error {
+	if len(c.ClientId) == 0 {
+		return fmt.Errorf("%w: missing clientId", ErrAuthFailed)
+	}
+
+	switch c.Type {
+	case ScriptApp:
+		if len(c.Username) == 0 || len(c.Password) == 0 {
+			return fmt.Errorf("%w: script apps need a username and password", ErrAuthFailed)
+		}
+	case InstalledApp:
+	default:
+		return fmt.Errorf("%w: unrecognized app type %s", ErrAuthFailed, c.Type)
+	}
+
+	return nil
+}
+
+// Credentials file exists, so home and subscriptions should come from the user's account
+func HasCredentials(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"reddittui/client/common"
+	"slices"
+	"strings"
+)
+
+const (
+	subredditKind = "t5"
+
+	// At most 1000 subscriptions are listed, 100 per page
+	maxSubscriptionPages = 10
+)
+
+type subredditData struct {
+	DisplayName string `json:"display_name"`
+}
+
+// Names of the subreddits the user is subscribed to, sorted alphabetically
+func GetSubscriptions(ctx context.Context, httpClient *http.Client, apiUrl string) ([]string, error) {
+	var (
+		subscriptions []string
+		after         string
+	)
+
+	for range maxSubscriptionPages {
+		query := url.Values{}
+		query.Set("limit", "100")
+		if len(after) > 0 {
+			query.Set("after", after)
+		}
+
+		listing, err := getListing(ctx, httpClient, apiUrl+"/subreddits/mine/subscriber?"+query.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, child := range listing.Data.Children {
+			var subreddit subredditData
+			if child.Kind != subredditKind {
+				continue
+			} else if err := json.Unmarshal(child.Data, &subreddit); err != nil {
+				slog.Debug("Error decoding subreddit", "error", err)
+				continue
+			}
+
+			subscriptions = append(subscriptions, subreddit.DisplayName)
+		}
+
+		after = listing.Data.After
+		if len(after) == 0 {
+			break
+		}
+	}
+
+	slices.SortFunc(subscriptions, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+
+	return subscriptions, nil
+}
+
+func getListing(ctx context.Context, httpClient *http.Client, listingUrl string) (common.Listing, error) {
+	var listing common.Listing
+
+	req, err := http.NewRequestWithContext(ctx, "GET", listingUrl, nil)
+	if err != nil {
+		return listing, err
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return listing, common.WrapRequestError(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return listing, common.NewResponseError(res)
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&listing)
+	return listing, err
+}
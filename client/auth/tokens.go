@@ -0,0 +1,291 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Access tokens are refreshed this long before they expire so requests in flight do not fail
+const expiryMargin = time.Minute
+
+type token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresIn    int       `json:"expires_in,omitempty"`
+	Expiry       time.Time `json:"expiry"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// Hands out access tokens for the api, requesting new ones as they expire. Script apps get a new token with
+// their password, installed apps use the refresh token saved by Login
+type TokenSource struct {
+	credentials Credentials
+	client      *http.Client
+	path        string
+	now         func() time.Time
+
+	mu    *sync.Mutex
+	token token
+}
+
+func NewTokenSource(credentials Credentials, httpClient *http.Client, tokenPath string) *TokenSource {
+	s := &TokenSource{
+		credentials: credentials,
+		client:      httpClient,
+		path:        tokenPath,
+		now:         time.Now,
+		mu:          &sync.Mutex{},
+	}
+
+	s.load()
+	return s
+}
+
+// Whether tokens can be requested without logging in first
+func (s *TokenSource) IsLoggedIn() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.credentials.Type == ScriptApp || len(s.token.RefreshToken) > 0
+}
+
+// Access token for the next api request
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.token.AccessToken) > 0 && s.now().Add(expiryMargin).Before(s.token.Expiry) {
+		return s.token.AccessToken, nil
+	}
+
+	form := url.Values{}
+	switch {
+	case s.credentials.Type == ScriptApp:
+		form.Set("grant_type", "password")
+		form.Set("username", s.credentials.Username)
+		form.Set("password", s.credentials.Password)
+	case len(s.token.RefreshToken) > 0:
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", s.token.RefreshToken)
+	default:
+		return "", ErrNotLoggedIn
+	}
+
+	if err := s.requestToken(ctx, form); err != nil {
+		return "", err
+	}
+
+	return s.token.AccessToken, nil
+}
+
+// Forget the access token after the api rejects it, the next request gets a new one
+func (s *TokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token.AccessToken = ""
+}
+
+// Authorize reddittui in the browser and save the refresh token. Script apps only check their credentials
+func (s *TokenSource) Login(ctx context.Context, openUrl func(string) error) error {
+	if s.credentials.Type == ScriptApp {
+		_, err := s.Token(ctx)
+		return err
+	}
+
+	redirect, err := url.Parse(s.credentials.RedirectUri)
+	if err != nil {
+		return fmt.Errorf("invalid redirectUri %s: %w", s.credentials.RedirectUri, err)
+	}
+
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return fmt.Errorf("could not listen for the login redirect on %s: %w", redirect.Host, err)
+	}
+
+	return s.login(ctx, listener, openUrl)
+}
+
+func (s *TokenSource) login(ctx context.Context, listener net.Listener, openUrl func(string) error) error {
+	state, err := randomState()
+	if err != nil {
+		return err
+	}
+
+	redirect, err := url.Parse(s.credentials.RedirectUri)
+	if err != nil {
+		return err
+	}
+
+	codes := make(chan string, 1)
+	errs := make(chan error, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(redirect.Path, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("state") != state {
+			// Not the redirect for this login, i.e. a stale browser tab
+			http.Error(w, "Login failed, please try again", http.StatusBadRequest)
+			return
+		}
+
+		if len(query.Get("error")) > 0 {
+			http.Error(w, "Login failed, please try again", http.StatusBadRequest)
+			select {
+			case errs <- fmt.Errorf("%w: %s", ErrAuthFailed, query.Get("error")):
+			default:
+			}
+			return
+		}
+
+		fmt.Fprintln(w, "Logged in to reddittui, you can close this window")
+		select {
+		case codes <- query.Get("code"):
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	authorizeUrl, err := url.Parse(s.credentials.AuthorizeUrl)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("client_id", s.credentials.ClientId)
+	query.Set("response_type", "code")
+	query.Set("state", state)
+	query.Set("redirect_uri", s.credentials.RedirectUri)
+	query.Set("duration", "permanent")
+	query.Set("scope", scopes)
+	authorizeUrl.RawQuery = query.Encode()
+
+	if err := openUrl(authorizeUrl.String()); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errs:
+		return err
+	case code := <-codes:
+		form := url.Values{}
+		form.Set("grant_type", "authorization_code")
+		form.Set("code", code)
+		form.Set("redirect_uri", s.credentials.RedirectUri)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.requestToken(ctx, form)
+	}
+}
+
+// Forget the saved refresh token
+func (s *TokenSource) Logout() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = token{}
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *TokenSource) requestToken(ctx context.Context, form url.Values) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", s.credentials.TokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.credentials.ClientId, s.credentials.ClientSecret)
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	var received token
+	if err := json.NewDecoder(res.Body).Decode(&received); err != nil && res.StatusCode == http.StatusOK {
+		return err
+	}
+
+	// Reddit reports some failures such as wrong passwords with a 200 and an error field
+	if res.StatusCode != http.StatusOK || len(received.Error) > 0 || len(received.AccessToken) == 0 {
+		reason := received.Error
+		if len(reason) == 0 {
+			reason = res.Status
+		}
+
+		return fmt.Errorf("%w: %s", ErrAuthFailed, reason)
+	}
+
+	received.Expiry = s.now().Add(time.Duration(received.ExpiresIn) * time.Second)
+	if len(received.RefreshToken) == 0 {
+		// Refreshing an access token does not always return a new refresh token
+		received.RefreshToken = s.token.RefreshToken
+	}
+
+	s.token = received
+	if err := s.save(); err != nil {
+		slog.Warn("Could not save token", "path", s.path, "error", err)
+	}
+
+	return nil
+}
+
+func (s *TokenSource) load() {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	} else if err != nil {
+		slog.Warn("Could not read token", "path", s.path, "error", err)
+		return
+	}
+
+	if err := json.Unmarshal(data, &s.token); err != nil {
+		slog.Warn("Could not decode token", "path", s.path, "error", err)
+	}
+}
+
+func (s *TokenSource) save() error {
+	data, err := json.Marshal(s.token)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0750); err != nil {
+		return err
+	}
+
+	// Tokens give access to the account, keep them private
+	return os.WriteFile(s.path, data, 0600)
+}
+
+func randomState() (string, error) {
+	state := make([]byte, 16)
+	if _, err := rand.Read(state); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(state), nil
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Round tripper that adds an access token to requests for the api. Requests for other hosts are sent as is
+type Transport struct {
+	Transport http.RoundTripper
+	Tokens    *TokenSource
+	apiHost   string
+}
+
+func NewTransport(transport http.RoundTripper, tokens *TokenSource) Transport {
+	var apiHost string
+	if apiUrl, err := url.Parse(tokens.credentials.ApiUrl); err == nil {
+		apiHost = apiUrl.Host
+	}
+
+	return Transport{
+		Transport: transport,
+		Tokens:    tokens,
+		apiHost:   apiHost,
+	}
+}
+
+func (t Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.apiHost {
+		return t.Transport.RoundTrip(req)
+	}
+
+	accessToken, err := t.Tokens.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "bearer "+accessToken)
+
+	res, err := t.Transport.RoundTrip(req)
+	if err == nil && res.StatusCode == http.StatusUnauthorized {
+		// The token was revoked or expired early
+		t.Tokens.Invalidate()
+	}
+
+	return res, err
+}
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/fs"
@@ -15,14 +16,17 @@ import (
 )
 
 type CommentsCache interface {
-	Get(path string) (model.Comments, error)
-	Put(comments model.Comments, path string) error
+	Get(ctx context.Context, path string) (model.Comments, error)
+	Put(ctx context.Context, comments model.Comments, path string) error
 	Clean()
 }
 
 type FileCommentsCache struct {
 	BaseUrl      string
 	CacheBaseDir string
+
+	// How long expired comments are kept around to show while they are refreshed
+	MaxStaleAge time.Duration
 }
 
 func NewFileCommentsCache(baseUrl, cacheDir string) FileCommentsCache {
@@ -34,7 +38,11 @@ func NewFileCommentsCache(baseUrl, cacheDir string) FileCommentsCache {
 
 // Get comments stored in cached file.
 // Returns comments if they are present and not expired
-func (f FileCommentsCache) Get(filename string) (comments model.Comments, err error) {
+func (f FileCommentsCache) Get(ctx context.Context, filename string) (comments model.Comments, err error) {
+	if err := ctx.Err(); err != nil {
+		return comments, err
+	}
+
 	subreddit := f.GetSubredditFromUrl(filename)
 	if len(subreddit) == 0 {
 		return comments, common.ErrNotFound
@@ -69,7 +77,12 @@ func (f FileCommentsCache) Get(filename string) (comments model.Comments, err er
 }
 
 // Cache the comments, writing the contents to the given cache file
-func (f FileCommentsCache) Put(comments model.Comments, filename string) error {
+func (f FileCommentsCache) Put(ctx context.Context, comments model.Comments, filename string) error {
+	// Comments fetched for a cancelled request are thrown away rather than cached
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	subreddit := f.GetSubredditFromUrl(filename)
 
 	cacheDir := filepath.Join(f.CacheBaseDir, subreddit)
@@ -146,7 +159,7 @@ func (f FileCommentsCache) Clean() {
 		}
 
 		// Delete cached comments file if it is expired
-		if time.Now().After(comments.Expiry) {
+		if time.Now().After(comments.Expiry.Add(f.MaxStaleAge)) {
 			err = os.Remove(path)
 			if err != nil {
 				slog.Warn("Could not delete expired cache file")
@@ -203,11 +216,11 @@ func NewNoOpCommentsCache() NoOpCommentsCache {
 	return NoOpCommentsCache{}
 }
 
-func (n NoOpCommentsCache) Get(cacheFilePath string) (comments model.Comments, err error) {
+func (n NoOpCommentsCache) Get(ctx context.Context, cacheFilePath string) (comments model.Comments, err error) {
 	return comments, common.ErrNotFound
 }
 
-func (n NoOpCommentsCache) Put(comments model.Comments, cacheFilePath string) error {
+func (n NoOpCommentsCache) Put(ctx context.Context, comments model.Comments, cacheFilePath string) error {
 	return nil
 }
 
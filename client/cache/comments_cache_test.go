@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"fmt"
 	"reddittui/client/common"
 	"reddittui/model"
@@ -27,12 +28,12 @@ func TestCommentsCacheHappyPath(t *testing.T) {
 	expected := createTestComments(expiry)
 
 	commentsUrl := generateCommentsFileUrl(testSubreddit, "happy")
-	err := cache.Put(expected, commentsUrl)
+	err := cache.Put(context.Background(), expected, commentsUrl)
 	if err != nil {
 		t.Fatalf("could not put comments in comments cache: %v", err)
 	}
 
-	got, err := cache.Get(commentsUrl)
+	got, err := cache.Get(context.Background(), commentsUrl)
 	if err != nil {
 		t.Fatalf("expected no errors getting comments from cache: %v", err)
 	}
@@ -43,7 +44,7 @@ func TestCommentsCacheHappyPath(t *testing.T) {
 func TestCommentsCacheCacheNotFound(t *testing.T) {
 	cache := NewFileCommentsCache(testBaseUrl, t.TempDir())
 
-	if _, err := cache.Get("notfound.json"); err != common.ErrNotFound {
+	if _, err := cache.Get(context.Background(), "notfound.json"); err != common.ErrNotFound {
 		t.Fatalf("expected to not find comments in cache")
 	}
 }
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
 	"io/fs"
 	"log/slog"
@@ -13,13 +14,16 @@ import (
 )
 
 type PostsCache interface {
-	Get(path string) (model.Posts, error)
-	Put(posts model.Posts, cacheFilePath string) error
+	Get(ctx context.Context, path string) (model.Posts, error)
+	Put(ctx context.Context, posts model.Posts, cacheFilePath string) error
 	Clean()
 }
 
 type FilePostsCache struct {
 	CacheBaseDir string
+
+	// How long expired posts are kept around to show while they are refreshed
+	MaxStaleAge time.Duration
 }
 
 func NewFilePostsCache(cacheDir string) FilePostsCache {
@@ -28,7 +32,11 @@ func NewFilePostsCache(cacheDir string) FilePostsCache {
 
 // Get posts stored in cached file.
 // Returns posts if they are present and not expired
-func (f FilePostsCache) Get(filename string) (posts model.Posts, err error) {
+func (f FilePostsCache) Get(ctx context.Context, filename string) (posts model.Posts, err error) {
+	if err := ctx.Err(); err != nil {
+		return posts, err
+	}
+
 	sanitizedFilename := url.QueryEscape(filename) + ".json"
 	cacheFilePath := filepath.Join(f.CacheBaseDir, sanitizedFilename)
 
@@ -57,7 +65,12 @@ func (f FilePostsCache) Get(filename string) (posts model.Posts, err error) {
 }
 
 // Cache the posts, writing the contents to the given cache file
-func (f FilePostsCache) Put(posts model.Posts, filename string) error {
+func (f FilePostsCache) Put(ctx context.Context, posts model.Posts, filename string) error {
+	// Posts fetched for a cancelled request are thrown away rather than cached
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	sanitizedFilename := url.QueryEscape(filename) + ".json"
 	cacheFilePath := filepath.Join(f.CacheBaseDir, sanitizedFilename)
 
@@ -119,7 +132,7 @@ func (f FilePostsCache) Clean() {
 		}
 
 		// Delete cached posts file if it is expired
-		if time.Now().After(posts.Expiry) {
+		if time.Now().After(posts.Expiry.Add(f.MaxStaleAge)) {
 			slog.Debug("Removing expired cache posts", "path", path)
 			err = os.Remove(path)
 			if err != nil {
@@ -138,11 +151,11 @@ func NewNoOpPostsCache() NoOpPostsCache {
 	return NoOpPostsCache{}
 }
 
-func (n NoOpPostsCache) Get(cacheFilePath string) (posts model.Posts, err error) {
+func (n NoOpPostsCache) Get(ctx context.Context, cacheFilePath string) (posts model.Posts, err error) {
 	return posts, common.ErrNotFound
 }
 
-func (n NoOpPostsCache) Put(posts model.Posts, cacheFilePath string) error {
+func (n NoOpPostsCache) Put(ctx context.Context, posts model.Posts, cacheFilePath string) error {
 	return nil
 }
 
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"reddittui/client/common"
@@ -29,12 +30,12 @@ func TestPostsCacheHappyPath(t *testing.T) {
 
 	expiry := time.Now().Add(200 * time.Millisecond).Round(time.Millisecond)
 	expected := createTestPosts(expiry)
-	err := cache.Put(expected, "happy")
+	err := cache.Put(context.Background(), expected, "happy")
 	if err != nil {
 		t.Fatalf("could not put posts in posts cache: %v", err)
 	}
 
-	got, err := cache.Get("happy")
+	got, err := cache.Get(context.Background(), "happy")
 	if err != nil {
 		t.Fatalf("expected no errors getting posts from cache: %v", err)
 	}
@@ -45,11 +46,26 @@ func TestPostsCacheHappyPath(t *testing.T) {
 func TestPostsCacheNotFound(t *testing.T) {
 	cache := NewFilePostsCache(t.TempDir())
 
-	if _, err := cache.Get("notfound.json"); err != common.ErrNotFound {
+	if _, err := cache.Get(context.Background(), "notfound.json"); err != common.ErrNotFound {
 		t.Fatalf("expected to not find posts in cache")
 	}
 }
 
+func TestPostsCacheCancelled(t *testing.T) {
+	cache := NewFilePostsCache(t.TempDir())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := cache.Put(ctx, createTestPosts(time.Now().Add(time.Hour)), "cancelled"); err != context.Canceled {
+		t.Fatalf("expected cancelled put to fail, got %v", err)
+	}
+
+	if _, err := cache.Get(context.Background(), "cancelled"); err != common.ErrNotFound {
+		t.Fatalf("expected posts from a cancelled request to not be cached, got %v", err)
+	}
+}
+
 func TestPostsCacheCannotDecodePosts(t *testing.T) {
 	cache := NewFilePostsCache(t.TempDir())
 
@@ -66,7 +82,7 @@ func TestPostsCacheCannotDecodePosts(t *testing.T) {
 	filename := filepath.Base(file.Name())
 	cacheEntryName := strings.TrimSuffix(filename, filepath.Ext(filename))
 
-	if _, err = cache.Get(cacheEntryName); err != common.ErrCannotDecodeCacheFile {
+	if _, err = cache.Get(context.Background(), cacheEntryName); err != common.ErrCannotDecodeCacheFile {
 		t.Fatalf("expected cannot decode cache entry %s, got %v", filename, err)
 	}
 }
@@ -76,7 +92,7 @@ func TestPostsCacheCacheExpired(t *testing.T) {
 
 	expiry := time.Now().Round(time.Millisecond)
 	expected := createTestPosts(expiry)
-	err := cache.Put(expected, "expired")
+	err := cache.Put(context.Background(), expected, "expired")
 	if err != nil {
 		t.Fatalf("could not put posts in posts cache: %v", err)
 	}
@@ -84,7 +100,7 @@ func TestPostsCacheCacheExpired(t *testing.T) {
 	// Posts should be already expired by time we fetch them
 	time.Sleep(100 * time.Millisecond)
 
-	_, err = cache.Get("happy")
+	_, err = cache.Get(context.Background(), "happy")
 	if err == nil {
 		t.Fatalf("expected no errors getting posts from cache: %v", err)
 	}
@@ -99,16 +115,16 @@ func TestPostsCacheCleanCache(t *testing.T) {
 	posts1.Subreddit = "subreddit1"
 	posts2.Subreddit = "subreddit2"
 
-	cache.Put(posts1, "subreddit1")
-	cache.Put(posts2, "subreddit2")
+	cache.Put(context.Background(), posts1, "subreddit1")
+	cache.Put(context.Background(), posts2, "subreddit2")
 
 	cache.Clean()
 
-	if _, err := cache.Get("subreddit1"); err != common.ErrNotFound {
+	if _, err := cache.Get(context.Background(), "subreddit1"); err != common.ErrNotFound {
 		t.Fatal("expected expired posts subreddit1 to be cleaned from cache")
 	}
 
-	gotPosts2, err := cache.Get("subreddit2")
+	gotPosts2, err := cache.Get(context.Background(), "subreddit2")
 	if err != nil {
 		t.Fatalf("unexpected error fetching subreddit2 from cache: %v", err)
 	}
@@ -118,7 +134,7 @@ func TestPostsCacheCleanCache(t *testing.T) {
 	time.Sleep(200 * time.Millisecond)
 	cache.Clean()
 
-	if _, err := cache.Get("subreddit2"); err != common.ErrNotFound {
+	if _, err := cache.Get(context.Background(), "subreddit2"); err != common.ErrNotFound {
 		t.Fatal("expected expired posts subreddit1 to be cleaned from cache")
 	}
 }
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"log"
 	"log/slog"
 	"net/http"
@@ -18,51 +19,253 @@ import (
 
 type RedditClient struct {
 	BaseUrl        string
+	instances      *common.InstancePool
+	offline        *common.OfflineMode
+	over18         *common.Over18Consent
+	account        *account
 	postsClient    posts.RedditPostsClient
 	commentsClient comments.RedditCommentsClient
+	prefetcher     *comments.Prefetcher
+	prefetchDepth  int
 }
 
 func NewRedditClient(configuration config.Config) RedditClient {
-	baseUrl, err := NormalizeBaseUrl(configuration.Server.Domain)
-	if err != nil {
-		log.Fatalf("Could not parse reddit server url: %s", configuration.Server.Domain)
-	}
+	instanceUrls := GetInstanceUrls(configuration.Server)
+
+	// Urls and cache keys are always built from the first instance
+	baseUrl := instanceUrls[0]
 
 	// Support legacy core.ClientTimeout configuration value, use the greater of the two
 	timeoutSeconds := max(configuration.Core.ClientTimeout, configuration.Client.TimeoutSeconds)
+	timeout := time.Duration(timeoutSeconds) * time.Second
+	cooldown := time.Duration(configuration.Server.CooldownSeconds) * time.Second
+
+	transport := newProxyTransport(configuration)
+
+	// Each attempt gets the full timeout, so there is no overall timeout on the http client.
+	// Failed requests are first retried against the other instances, then retried with backoff
+	instances, err := common.NewInstancePool(instanceUrls, cooldown, timeout, transport)
+	if err != nil {
+		log.Fatalf("Could not parse reddit server urls: %v", instanceUrls)
+	}
+
+	retryDelay := time.Duration(configuration.Client.RetryDelayMillis) * time.Millisecond
+	over18 := newOver18Consent()
+	retryTransport := common.NewRetryTransport(instances, configuration.Client.MaxRetries, retryDelay)
+	headerTransport := common.NewHeaderTransport(retryTransport, configuration.Client.UserAgent, configuration.Client.Headers)
+	headerTransport.Over18 = over18
+
 	httpClient := &http.Client{
-		Timeout: time.Duration(timeoutSeconds) * time.Second,
+		Transport: headerTransport,
+		Jar:       newCookieJar(configuration),
 	}
 
-	postsCache, commentsCache := InitializeCaches(baseUrl, configuration.Core.BypassCache)
+	maxStaleAge := time.Duration(configuration.Client.MaxStaleSeconds) * time.Second
+	postsCache, commentsCache := InitializeCaches(baseUrl, configuration.Core.BypassCache, maxStaleAge)
+	// Both clients share the offline mode so either one detecting the network is down affects the other
+	offline := common.NewOfflineMode(configuration.Core.Offline)
+
 	postsClient := posts.NewRedditPostsClient(baseUrl, httpClient, postsCache, configuration)
-	commentsClient := comments.NewRedditCommentsClient(baseUrl, configuration.Server.Type, httpClient, commentsCache)
+	postsClient.Offline = offline
+
+	commentsClient := comments.NewRedditCommentsClient(baseUrl, httpClient, commentsCache, configuration)
+	commentsClient.Offline = offline
 
 	return RedditClient{
 		baseUrl,
+		instances,
+		offline,
+		over18,
+		newAccount(configuration, baseUrl, headerTransport, postsCache, offline),
 		postsClient,
 		commentsClient,
+		newPrefetcher(commentsClient, configuration),
+		configuration.Client.PrefetchDepth,
+	}
+}
+
+func newProxyTransport(configuration config.Config) *http.Transport {
+	proxy, err := common.NewProxySelector(configuration.Client.Proxy, configuration.Client.Proxies, configuration.Client.NoProxy)
+	if err != nil {
+		log.Fatalf("Could not parse proxy configuration: %v", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy.Proxy
+	return transport
+}
+
+// Requests outside of browsing, such as logging in, only need the proxy and headers
+func newTransport(configuration config.Config) http.RoundTripper {
+	return common.NewHeaderTransport(newProxyTransport(configuration), configuration.Client.UserAgent, configuration.Client.Headers)
+}
+
+// Cookies are only kept when enabled in the configuration. They are stored in the state directory
+func newCookieJar(configuration config.Config) http.CookieJar {
+	if !configuration.Client.Cookies {
+		return nil
+	}
+
+	stateDir, err := utils.GetStateDir()
+	if err != nil {
+		slog.Warn("Cannot open state dir, skipping cookies", "error", err)
+		return nil
+	}
+
+	jar, err := common.NewPersistentJar(filepath.Join(stateDir, common.CookiesFileName))
+	if err != nil {
+		slog.Warn("Cannot create cookie jar, skipping cookies", "error", err)
+		return nil
+	}
+
+	return jar
+}
+
+// The over 18 confirmation is saved in the state directory. It only lasts for this run if there is no state directory
+func newOver18Consent() *common.Over18Consent {
+	stateDir, err := utils.GetStateDir()
+	if err != nil {
+		slog.Warn("Cannot open state dir, over 18 confirmation will not be saved", "error", err)
+		return common.NewOver18Consent("")
+	}
+
+	return common.NewOver18Consent(filepath.Join(stateDir, common.Over18FileName))
+}
+
+// Prefetched comments are only useful when they can be cached
+func newPrefetcher(commentsClient comments.RedditCommentsClient, configuration config.Config) *comments.Prefetcher {
+	clientConfig := configuration.Client
+	if !clientConfig.Prefetch || configuration.Core.BypassCache || clientConfig.PrefetchDepth <= 0 || clientConfig.PrefetchConcurrency <= 0 {
+		return nil
+	}
+
+	fetch := func(ctx context.Context, url string) error {
+		comments, err := commentsClient.GetComments(ctx, url, "")
+		if err == nil && comments.Stale {
+			_, err = commentsClient.RevalidateComments(ctx, url, comments.Sort)
+		}
+
+		return err
 	}
+
+	return comments.NewPrefetcher(fetch, clientConfig.PrefetchConcurrency)
+}
+
+// Normalized urls of the configured servers. A list of instances replaces the single domain
+func GetInstanceUrls(server config.ServerConfig) []string {
+	domains := server.Instances
+	if len(domains) == 0 {
+		domains = []string{server.Domain}
+	}
+
+	var instanceUrls []string
+	for _, domain := range domains {
+		instanceUrl, err := NormalizeBaseUrl(domain)
+		if err != nil {
+			log.Fatalf("Could not parse reddit server url: %s", domain)
+		}
+
+		instanceUrls = append(instanceUrls, instanceUrl)
+	}
+
+	return instanceUrls
+}
+
+// Posts and comments are being served from the cache, either because of the --offline flag or
+// because the server could not be reached
+func (r RedditClient) IsOffline() bool {
+	return r.offline.IsOffline()
 }
 
-func (r RedditClient) GetHomePosts(after string) (model.Posts, error) {
-	return r.postsClient.GetHomePosts(after)
+// Allow over 18 subreddits and posts to load, for this and every later run
+func (r RedditClient) ConfirmOver18() {
+	if err := r.over18.Confirm(); err != nil {
+		slog.Warn("Could not save over 18 confirmation", "error", err)
+	}
+}
+
+// Server currently handling requests. Empty when only one server is configured
+func (r RedditClient) ActiveInstance() string {
+	if r.instances.Size() <= 1 {
+		return ""
+	}
+
+	return r.instances.Active()
 }
 
-func (r RedditClient) GetSubredditPosts(subreddit, after string) (model.Posts, error) {
-	return r.postsClient.GetSubredditPosts(subreddit, after)
+func (r RedditClient) GetHomePosts(ctx context.Context, sort model.PostSort, after string) (model.Posts, error) {
+	return r.homePostsClient().GetHomePosts(ctx, sort, after)
 }
 
-func (r RedditClient) GetComments(url string) (model.Comments, error) {
-	return r.commentsClient.GetComments(url)
+func (r RedditClient) GetSubredditPosts(ctx context.Context, subreddit string, sort model.PostSort, after string) (model.Posts, error) {
+	return r.postsClient.GetSubredditPosts(ctx, subreddit, sort, after)
 }
 
+func (r RedditClient) SearchPosts(ctx context.Context, query, subreddit string, sort model.PostSort, after string) (model.Posts, error) {
+	return r.postsClient.SearchPosts(ctx, query, subreddit, sort, after)
+}
+
+func (r RedditClient) GetUserPosts(ctx context.Context, user string, sort model.PostSort, after string) (model.Posts, error) {
+	return r.postsClient.GetUserPosts(ctx, user, sort, after)
+}
+
+// Fetch a fresh copy of posts that were served stale from the cache
+func (r RedditClient) RevalidatePosts(ctx context.Context, stale model.Posts) (model.Posts, error) {
+	if stale.Kind() == model.HomePosts {
+		return r.homePostsClient().RevalidatePosts(ctx, stale)
+	}
+
+	return r.postsClient.RevalidatePosts(ctx, stale)
+}
+
+func (r RedditClient) GetComments(ctx context.Context, url, sort string) (model.Comments, error) {
+	return r.commentsClient.GetComments(ctx, url, sort)
+}
+
+// Fetch a fresh copy of comments that were served stale from the cache
+func (r RedditClient) RevalidateComments(ctx context.Context, url, sort string) (model.Comments, error) {
+	return r.commentsClient.RevalidateComments(ctx, url, sort)
+}
+
+func (r RedditClient) GetMoreComments(ctx context.Context, postId string, stub model.Comment, sort string) ([]model.Comment, error) {
+	return r.commentsClient.GetMoreComments(ctx, postId, stub, sort)
+}
+
+// Save comments with replies loaded since they were fetched to the cache
+func (r RedditClient) CacheComments(ctx context.Context, url string, comments model.Comments) error {
+	return r.commentsClient.CacheComments(ctx, url, comments)
+}
+
+// Load the comments for the posts around the cursor into the cache, nearest posts first
+func (r RedditClient) PrefetchComments(posts []model.Post, cursor int) {
+	if r.prefetcher == nil || r.IsOffline() || cursor < 0 || cursor >= len(posts) {
+		return
+	}
+
+	urls := []string{posts[cursor].CommentsUrl}
+	for distance := 1; distance <= r.prefetchDepth; distance++ {
+		if next := cursor + distance; next < len(posts) {
+			urls = append(urls, posts[next].CommentsUrl)
+		}
+		if prev := cursor - distance; prev >= 0 {
+			urls = append(urls, posts[prev].CommentsUrl)
+		}
+	}
+
+	r.prefetcher.Prefetch(urls)
+}
+
+// Expired entries are all there is to show while offline, so they are kept
 func (r RedditClient) CleanCache() {
+	if r.IsOffline() {
+		return
+	}
+
 	r.postsClient.Cache.Clean()
 	r.commentsClient.Cache.Clean()
 }
 
-func InitializeCaches(baseUrl string, bypassCache bool) (cache.PostsCache, cache.CommentsCache) {
+func InitializeCaches(baseUrl string, bypassCache bool, maxStaleAge time.Duration) (cache.PostsCache, cache.CommentsCache) {
 	if bypassCache {
 		return cache.NewNoOpPostsCache(), cache.NewNoOpCommentsCache()
 	}
@@ -83,6 +286,7 @@ func InitializeCaches(baseUrl string, bypassCache bool) (cache.PostsCache, cache
 
 	// use root cache dir for posts
 	postsCache := cache.NewFilePostsCache(cacheDir)
+	postsCache.MaxStaleAge = maxStaleAge
 
 	// ensure comments cache dir exists
 	commentsCacheDir := filepath.Join(cacheDir, common.CommentsCacheDirName)
@@ -93,5 +297,6 @@ func InitializeCaches(baseUrl string, bypassCache bool) (cache.PostsCache, cache
 	}
 
 	commentsCache := cache.NewFileCommentsCache(baseUrl, commentsCacheDir)
+	commentsCache.MaxStaleAge = maxStaleAge
 	return postsCache, commentsCache
 }
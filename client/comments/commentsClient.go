@@ -1,98 +1,311 @@
 package comments
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"reddittui/client/cache"
 	"reddittui/client/common"
+	"reddittui/config"
 	"reddittui/model"
 	"reddittui/utils"
 	"regexp"
+	"slices"
+	"strings"
 	"time"
-
-	"golang.org/x/net/html"
 )
 
-const defaultTtl = 1 * time.Hour
+const (
+	defaultTtl = 1 * time.Hour
+
+	// The morechildren api accepts at most 100 comment ids per request
+	maxMoreChildren = 100
+)
 
-var postTextTrimRegex = regexp.MustCompile("\n\n\n+")
+var (
+	subredditRegex = regexp.MustCompile("/r/([^/?]+)")
+)
 
 type RedditCommentsClient struct {
-	BaseUrl string
-	Client  *http.Client
-	Cache   cache.CommentsCache
-	Parser  CommentsParser
+	BaseUrl        string
+	ServerType     string
+	CacheTtl       time.Duration
+	Client         *http.Client
+	Cache          cache.CommentsCache
+	Parser         CommentsParser
+	DefaultSort    string
+	SubredditSorts map[string]string
+	Offline        *common.OfflineMode
 }
 
-func NewRedditCommentsClient(baseUrl, serverType string, httpClient *http.Client, commentsCache cache.CommentsCache) RedditCommentsClient {
-	var parser CommentsParser
+func NewRedditCommentsClient(
+	baseUrl string,
+	httpClient *http.Client,
+	commentsCache cache.CommentsCache,
+	configuration config.Config,
+) RedditCommentsClient {
+	var (
+		parser     CommentsParser
+		serverType = configuration.Server.Type
+	)
 
 	switch serverType {
 	case "old":
 		parser = OldRedditCommentsParser{}
 	case "redlib":
 		parser = RedlibCommentsParser{}
+	case "json":
+		parser = JsonCommentsParser{}
 	default:
 		panic("Unrecognized server type in configuration: " + serverType)
 	}
 
+	subredditSorts := make(map[string]string)
+	for subreddit, sort := range configuration.Comments.SubredditSorts {
+		subreddit = strings.TrimPrefix(strings.ToLower(subreddit), "r/")
+		subredditSorts[subreddit] = model.NormalizeCommentSort(sort)
+	}
+
+	cacheTtl := time.Duration(configuration.Client.CacheTtlSeconds) * time.Second
+	if cacheTtl <= 0 {
+		cacheTtl = defaultTtl
+	}
+
 	return RedditCommentsClient{
-		BaseUrl: baseUrl,
-		Client:  httpClient,
-		Cache:   commentsCache,
-		Parser:  parser,
+		BaseUrl:        baseUrl,
+		ServerType:     serverType,
+		CacheTtl:       cacheTtl,
+		Client:         httpClient,
+		Cache:          commentsCache,
+		Parser:         parser,
+		DefaultSort:    model.NormalizeCommentSort(configuration.Comments.DefaultSort),
+		SubredditSorts: subredditSorts,
 	}
 }
 
-func (r RedditCommentsClient) GetComments(url string) (comments model.Comments, err error) {
+// Get comments for the post at url. If sort is empty, the configured sort for the post's subreddit is used.
+// Expired comments are returned marked as stale for the caller to revalidate
+func (r RedditCommentsClient) GetComments(ctx context.Context, url, sort string) (comments model.Comments, err error) {
 	totalTimer := utils.NewTimer("total time to retrieve comments")
 	defer totalTimer.StopAndLog()
 
+	if sort == "" {
+		sort = r.GetDefaultSort(url)
+	}
+
 	timer := utils.NewTimer("fetching comments from cache")
-	comments, err = r.Cache.Get(url)
+	comments, err = r.Cache.Get(ctx, r.cacheKey(url, sort))
+	timer.StopAndLog()
+
 	if err == nil {
 		// return cached data
-		timer.StopAndLog()
 		return comments, nil
+	} else if errors.Is(err, common.ErrCacheEntryExpired) {
+		// There is no server to refresh expired comments from while offline
+		comments.Stale = !r.Offline.IsOffline()
+		return comments, nil
+	}
+
+	return r.fetchAndCacheComments(ctx, url, sort)
+}
+
+// Fetch a fresh copy of stale comments from the server, skipping the cache
+func (r RedditCommentsClient) RevalidateComments(ctx context.Context, url, sort string) (model.Comments, error) {
+	timer := utils.NewTimer("total time to revalidate comments")
+	defer timer.StopAndLog()
+
+	if sort == "" {
+		sort = r.GetDefaultSort(url)
+	}
+
+	return r.fetchAndCacheComments(ctx, url, sort)
+}
+
+func (r RedditCommentsClient) fetchAndCacheComments(ctx context.Context, url, sort string) (comments model.Comments, err error) {
+	requestUrl := common.AddQueryParameter(r.requestUrl(url), common.LimitQueryParameter)
+	if sort != "" {
+		requestUrl = common.AddQueryParameter(requestUrl, fmt.Sprintf("sort=%s", sort))
 	}
+
+	comments, err = r.fetchComments(ctx, url, requestUrl)
+	if err != nil {
+		return comments, err
+	}
+	comments.Fetched = time.Now()
+	comments.Expiry = comments.Fetched.Add(r.CacheTtl)
+	comments.Sort = sort
+
+	timer := utils.NewTimer("putting comments in cache")
+	r.Cache.Put(ctx, comments, r.cacheKey(url, sort))
 	timer.StopAndLog()
 
-	urlWithLimit := common.AddQueryParameter(url, common.LimitQueryParameter)
-	req, err := http.NewRequest("GET", urlWithLimit, nil)
+	return comments, nil
+}
+
+// Write comments the page has changed back to the cache, such as after loading more replies, so
+// reopening the thread shows them too. The comments keep their original expiry
+func (r RedditCommentsClient) CacheComments(ctx context.Context, url string, comments model.Comments) error {
+	return r.Cache.Put(ctx, comments, r.cacheKey(url, comments.Sort))
+}
+
+// Cache each sort of the same post separately
+func (r RedditCommentsClient) cacheKey(url, sort string) string {
+	if sort == "" {
+		return url
+	}
+
+	return common.AddQueryParameter(url, fmt.Sprintf("sort=%s", sort))
+}
+
+// Get the replies hidden behind a "load more comments" or "continue this thread" placeholder.
+// The returned comments are indented to replace the placeholder in the comments list
+func (r RedditCommentsClient) GetMoreComments(ctx context.Context, postId string, stub model.Comment, sort string) ([]model.Comment, error) {
+	timer := utils.NewTimer("total time to retrieve more comments")
+	defer timer.StopAndLog()
+
+	if len(stub.MoreIds) > 0 && r.ServerType != "redlib" {
+		return r.getMoreChildren(ctx, postId, stub, sort)
+	} else if stub.MoreUrl != "" {
+		return r.getDeeperThread(ctx, stub, sort)
+	}
+
+	return nil, common.ErrNotFound
+}
+
+// Fetch missing replies by id using the morechildren api
+func (r RedditCommentsClient) getMoreChildren(ctx context.Context, postId string, stub model.Comment, sort string) ([]model.Comment, error) {
+	var comments []model.Comment
+
+	for ids := range slices.Chunk(stub.MoreIds, maxMoreChildren) {
+		query := url.Values{}
+		query.Set("api_type", "json")
+		query.Set("link_id", common.LinkKind+"_"+postId)
+		query.Set("children", strings.Join(ids, ","))
+		query.Set("limit_children", "false")
+		if sort != "" {
+			query.Set("sort", sort)
+		}
+
+		requestUrl := fmt.Sprintf("%s/api/morechildren.json?%s", r.BaseUrl, query.Encode())
+		res, err := r.doRequest(ctx, requestUrl)
+		if err != nil {
+			return comments, err
+		}
+
+		children, err := JsonCommentsParser{}.ParseMoreChildren(res.Body, stub.Depth)
+		res.Body.Close()
+		if err != nil {
+			return comments, err
+		}
+
+		comments = append(comments, children...)
+	}
+
+	return comments, nil
+}
+
+// Fetch the thread rooted at the parent comment, keeping only the parent's replies
+func (r RedditCommentsClient) getDeeperThread(ctx context.Context, stub model.Comment, sort string) ([]model.Comment, error) {
+	threadUrl := stub.MoreUrl
+	if strings.HasPrefix(threadUrl, "/") {
+		threadUrl = r.BaseUrl + threadUrl
+	}
+
+	// Strip parameters such as ?context=3 which would include the parent's ancestors
+	if index := strings.Index(threadUrl, "?"); index >= 0 {
+		threadUrl = threadUrl[:index]
+	}
+
+	requestUrl := common.AddQueryParameter(r.requestUrl(threadUrl), common.LimitQueryParameter)
+	if sort != "" {
+		requestUrl = common.AddQueryParameter(requestUrl, fmt.Sprintf("sort=%s", sort))
+	}
+
+	thread, err := r.fetchComments(ctx, threadUrl, requestUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []model.Comment
+	for i, comment := range thread.Comments {
+		if i == 0 {
+			// Skip the parent comment, it is already displayed above the placeholder
+			continue
+		} else if comment.Depth == 0 {
+			break
+		}
+
+		comment.Depth += stub.Depth - 1
+		comments = append(comments, comment)
+	}
+
+	return comments, nil
+}
+
+func (r RedditCommentsClient) fetchComments(ctx context.Context, url, requestUrl string) (comments model.Comments, err error) {
+	res, err := r.doRequest(ctx, requestUrl)
 	if err != nil {
 		return comments, err
 	}
-	req.Header.Add(common.UserAgentHeaderKey, common.UserAgentHeaderValue)
 
-	timer = utils.NewTimer("fetching comments from server")
+	defer res.Body.Close()
+
+	timer := utils.NewTimer("converting comments")
+	comments, err = r.Parser.ParseComments(res.Body, url)
+	timer.StopAndLog()
+
+	return comments, err
+}
+
+func (r RedditCommentsClient) doRequest(ctx context.Context, requestUrl string) (*http.Response, error) {
+	if r.Offline.IsForced() {
+		return nil, common.ErrOffline
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", requestUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	timer := utils.NewTimer("fetching comments from server")
 	res, err := r.Client.Do(req)
-	timer.StopAndLog("url", url)
+	timer.StopAndLog("url", requestUrl)
+	r.Offline.Update(err)
 	if err != nil {
-		return comments, err
+		return nil, common.WrapRequestError(err)
 	}
 
 	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
 		slog.Error("Error fetching comments from server", "StatusCode", res.StatusCode)
-		return comments, common.ErrNotFound
+		return nil, common.NewResponseError(res)
+	} else if common.IsOver18Redirect(res) {
+		res.Body.Close()
+		return nil, common.ErrOver18
 	}
 
-	defer res.Body.Close()
+	return res, nil
+}
 
-	timer = utils.NewTimer("parsing comments html")
-	doc, err := html.Parse(res.Body)
-	timer.StopAndLog()
-	if err != nil {
-		return comments, err
+// Get the configured comment sort for the subreddit the post at url belongs to
+func (r RedditCommentsClient) GetDefaultSort(url string) string {
+	if matches := subredditRegex.FindStringSubmatch(url); len(matches) == 2 {
+		if sort, ok := r.SubredditSorts[strings.ToLower(matches[1])]; ok && sort != "" {
+			return sort
+		}
 	}
 
-	timer = utils.NewTimer("converting comments html")
-	comments = r.Parser.ParseComments(common.HtmlNode{Node: doc}, url)
-	comments.Expiry = time.Now().Add(defaultTtl)
-	timer.StopAndLog()
+	return r.DefaultSort
+}
 
-	timer = utils.NewTimer("putting comments in cache")
-	r.Cache.Put(comments, url)
-	timer.StopAndLog()
+// Url to request from the server. The json api serves the same comments under a .json suffix
+func (r RedditCommentsClient) requestUrl(url string) string {
+	if r.ServerType == "json" {
+		return common.JsonUrl(url)
+	}
 
-	return comments, nil
+	return url
 }
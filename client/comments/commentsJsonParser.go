@@ -0,0 +1,251 @@
+package comments
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"reddittui/client/common"
+	"reddittui/model"
+	"reddittui/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type JsonCommentsParser struct{}
+
+// The comments endpoint returns two listings, the first containing the post and the second
+// containing the top level comments with their replies nested inside
+func (p JsonCommentsParser) ParseComments(body io.Reader, url string) (model.Comments, error) {
+	var (
+		commentsData model.Comments
+		listings     []common.Listing
+	)
+
+	if err := json.NewDecoder(body).Decode(&listings); err != nil {
+		return commentsData, err
+	}
+
+	if len(listings) != 2 {
+		return commentsData, fmt.Errorf("expected 2 listings in comments response, got %d", len(listings))
+	}
+
+	now := time.Now()
+	for _, child := range listings[0].Data.Children {
+		if child.Kind != common.LinkKind {
+			continue
+		}
+
+		var link common.LinkData
+		if err := json.Unmarshal(child.Data, &link); err != nil {
+			return commentsData, err
+		}
+
+		p.parsePost(link, url, now, &commentsData)
+	}
+
+	commentsData.Comments = p.parseCommentsList(listings[1], url, 0, now, nil)
+	return commentsData, nil
+}
+
+func (p JsonCommentsParser) parsePost(link common.LinkData, url string, now time.Time, commentsData *model.Comments) {
+	commentsData.PostId = link.Id
+	commentsData.PostTitle = link.Title
+	commentsData.PostAuthor = link.Author
+	commentsData.Subreddit = link.Subreddit
+	commentsData.PostPoints = strconv.Itoa(link.Score)
+	commentsData.PostTimestamp = utils.FormatRelativeTime(common.FromUnixTimestamp(link.CreatedUtc), now)
+
+	if link.IsSelf {
+		commentsData.PostUrl = url
+		if link.SelftextHtml != "" {
+			text, source := renderEscapedHtml(link.SelftextHtml)
+			commentsData.PostText, commentsData.PostHtml = text+"\n\n", source
+		}
+		return
+	}
+
+	commentsData.PostUrl = link.Url
+	commentsData.PostText = fmt.Sprintf("%s\n\n", common.HyperLinkStyle.Render(link.Url))
+}
+
+func (p JsonCommentsParser) parseCommentsList(listing common.Listing, url string, depth int, now time.Time, comments []model.Comment) []model.Comment {
+	for _, child := range listing.Data.Children {
+		switch child.Kind {
+		case common.CommentKind:
+			var data common.CommentData
+			if err := json.Unmarshal(child.Data, &data); err != nil {
+				slog.Debug("Error decoding comment", "error", err)
+				continue
+			}
+
+			comments = append(comments, p.parseComment(data, depth, now))
+
+			if replies, ok := data.GetReplies(); ok {
+				comments = p.parseCommentsList(replies, url, depth+1, now, comments)
+			}
+
+		case common.MoreKind:
+			var data common.MoreData
+			if err := json.Unmarshal(child.Data, &data); err != nil {
+				slog.Debug("Error decoding more comments", "error", err)
+				continue
+			}
+
+			if comment, ok := p.parseMore(data, url, depth); ok {
+				comments = append(comments, comment)
+			}
+		}
+	}
+
+	return comments
+}
+
+// More placeholders without children are "continue this thread" links to the parent comment
+func (p JsonCommentsParser) parseMore(data common.MoreData, url string, depth int) (model.Comment, bool) {
+	comment := model.Comment{
+		Depth:     depth,
+		MoreIds:   data.Children,
+		MoreCount: data.Count,
+	}
+
+	if len(data.Children) > 0 {
+		return comment, true
+	}
+
+	parentId, ok := strings.CutPrefix(data.ParentId, common.CommentKind+"_")
+	if !ok || url == "" {
+		return comment, false
+	}
+
+	comment.MoreUrl = strings.TrimSuffix(url, "/") + "/" + parentId + "/"
+	return comment, true
+}
+
+func (p JsonCommentsParser) parseComment(data common.CommentData, depth int, now time.Time) model.Comment {
+	created := common.FromUnixTimestamp(data.CreatedUtc)
+
+	points := "[score hidden]"
+	if !data.ScoreHidden {
+		points = utils.GetSingularPlural(strconv.Itoa(data.Score), "point", "points")
+	}
+
+	text, source := renderEscapedHtml(data.BodyHtml)
+	comment := model.Comment{
+		Id:        data.Id,
+		Author:    data.Author,
+		Text:      text,
+		Html:      source,
+		Points:    points,
+		Score:     data.Score,
+		Timestamp: utils.FormatRelativeTime(created, now),
+		Created:   created,
+		Depth:     depth,
+		Vote:      common.VoteFromLikes(data.Likes),
+		Saved:     data.Saved,
+	}
+
+	if model.IsDeletedComment(data.Author, data.Body) {
+		return comment.AsDeleted()
+	}
+
+	return comment
+}
+
+// Render the escaped html found in body_html and selftext_html fields
+func renderEscapedHtml(escaped string) (text, source string) {
+	root, err := common.ParseEscapedHtml(escaped)
+	if err != nil {
+		slog.Debug("Error parsing embedded html", "error", err)
+		return "", ""
+	}
+
+	mdNode, ok := root.FindDescendant("div", "md")
+	if !ok {
+		return "", ""
+	}
+
+	return renderMarkdown(mdNode)
+}
+
+// Parse the flat list of comments returned by the morechildren api into a list ordered and indented
+// like a regular comments tree. Top level comments in the response are placed at depth
+func (p JsonCommentsParser) ParseMoreChildren(body io.Reader, depth int) ([]model.Comment, error) {
+	var response common.MoreChildrenResponse
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	if len(response.Json.Errors) > 0 {
+		return nil, fmt.Errorf("error loading more comments: %v", response.Json.Errors)
+	}
+
+	type node struct {
+		parentId string
+		comment  model.Comment
+	}
+
+	var (
+		now      = time.Now()
+		nodes    []node
+		known    = make(map[string]bool)
+		children = make(map[string][]int)
+	)
+
+	for _, thing := range response.Json.Data.Things {
+		switch thing.Kind {
+		case common.CommentKind:
+			var data common.CommentData
+			if err := json.Unmarshal(thing.Data, &data); err != nil {
+				slog.Debug("Error decoding comment", "error", err)
+				continue
+			}
+
+			known[data.Name] = true
+			nodes = append(nodes, node{data.ParentId, p.parseComment(data, 0, now)})
+
+		case common.MoreKind:
+			var data common.MoreData
+			if err := json.Unmarshal(thing.Data, &data); err != nil {
+				slog.Debug("Error decoding more comments", "error", err)
+				continue
+			}
+
+			// Continue this thread links cannot be built without the post url, skip them
+			if comment, ok := p.parseMore(data, "", 0); ok {
+				nodes = append(nodes, node{data.ParentId, comment})
+			}
+		}
+	}
+
+	var roots []int
+	for i, n := range nodes {
+		if known[n.parentId] {
+			children[n.parentId] = append(children[n.parentId], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+
+	var (
+		comments []model.Comment
+		walk     func(i, depth int)
+	)
+
+	walk = func(i, depth int) {
+		comment := nodes[i].comment
+		comment.Depth = depth
+		comments = append(comments, comment)
+
+		for _, child := range children[common.CommentKind+"_"+comment.Id] {
+			walk(child, depth+1)
+		}
+	}
+
+	for _, root := range roots {
+		walk(root, depth)
+	}
+
+	return comments, nil
+}
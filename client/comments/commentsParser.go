@@ -2,24 +2,43 @@ package comments
 
 import (
 	"fmt"
+	"io"
 	"reddittui/client/common"
+	"reddittui/client/render"
 	"reddittui/model"
 	"reddittui/utils"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"golang.org/x/net/html"
 )
 
+var (
+	moreChildrenArgsRegex = regexp.MustCompile(`'([^']*)'`)
+	moreCountRegex        = regexp.MustCompile(`(\d+)`)
+)
+
 type CommentsParser interface {
-	ParseComments(common.HtmlNode, string) model.Comments
+	ParseComments(io.Reader, string) (model.Comments, error)
 }
 
 type OldRedditCommentsParser struct{}
 
-func (p OldRedditCommentsParser) ParseComments(root common.HtmlNode, url string) model.Comments {
+func (p OldRedditCommentsParser) ParseComments(body io.Reader, url string) (model.Comments, error) {
+	root, err := parseHtml(body)
+	if err != nil {
+		return model.Comments{}, err
+	}
+
+	return p.parseComments(root, url), nil
+}
+
+func (p OldRedditCommentsParser) parseComments(root common.HtmlNode, url string) model.Comments {
 	var commentsData model.Comments
 	var commentsList []model.Comment
 
+	commentsData.PostId = p.getPostId(root)
 	commentsData.PostTitle = p.getTitle(root)
 	commentsData.PostAuthor = p.getPostAuthor(root)
 	commentsData.PostTimestamp = p.getPostTimestamp(root)
@@ -27,12 +46,13 @@ func (p OldRedditCommentsParser) ParseComments(root common.HtmlNode, url string)
 	commentsData.PostPoints = p.getPostPoints(root)
 	commentsData.Comments = p.parseCommentsList(root, 0, commentsList)
 
-	postText, postUrl := p.getPostContent(root)
+	postText, postHtml, postUrl := p.getPostContent(root)
 	if postUrl == "" {
 		// Self post
 		postUrl = url
 	}
 	commentsData.PostText = postText
+	commentsData.PostHtml = postHtml
 	commentsData.PostUrl = postUrl
 
 	return commentsData
@@ -49,19 +69,28 @@ func (p OldRedditCommentsParser) parseCommentsList(node common.HtmlNode, depth i
 		}
 	}
 
-	for c := range commentsNode.FindChildren("div", "thing", "comment") {
-		if c.ClassContains("deleted") {
-			// Skip deleted comments and their children
-			// todo: figure out how to render these properly
+	for c := range commentsNode.FindChildren("div", "thing") {
+		if c.ClassContains("morechildren") || c.ClassContains("morerecursion") {
+			if comment, ok := p.parseMoreNode(c, depth); ok {
+				comments = append(comments, comment)
+			}
+			continue
+		} else if !c.ClassContains("comment") {
 			continue
 		}
 
-		entryNode, ok := c.FindChild("div", "entry")
-		if !ok {
+		comment := model.Comment{Depth: depth}
+		if entryNode, ok := c.FindChild("div", "entry"); ok {
+			comment = p.parseCommentNode(entryNode, depth)
+		} else if !c.ClassContains("deleted") {
 			continue
 		}
 
-		comment := p.parseCommentNode(entryNode, depth)
+		comment.Id = strings.TrimPrefix(c.GetAttr("data-fullname"), common.CommentKind+"_")
+		if c.ClassContains("deleted") || model.IsDeletedComment(comment.Author, comment.Text) {
+			// Keep a placeholder so the replies below it are still shown
+			comment = comment.AsDeleted()
+		}
 		comments = append(comments, comment)
 
 		if n, ok := c.FindChild("div", "child"); ok {
@@ -72,6 +101,43 @@ func (p OldRedditCommentsParser) parseCommentsList(node common.HtmlNode, depth i
 	return comments
 }
 
+// Parse "load more comments" and "continue this thread" placeholders
+func (p OldRedditCommentsParser) parseMoreNode(node common.HtmlNode, depth int) (model.Comment, bool) {
+	comment := model.Comment{Depth: depth}
+
+	if moreNode, ok := node.FindDescendant("span", "morecomments"); ok {
+		linkNode, ok := moreNode.FindChild("a")
+		if !ok {
+			return comment, false
+		}
+
+		// onclick="return morechildren(this, 't3_abc', 'confidence', 'id1,id2', 'False')"
+		args := moreChildrenArgsRegex.FindAllStringSubmatch(linkNode.GetAttr("onclick"), -1)
+		if len(args) < 3 {
+			return comment, false
+		}
+
+		comment.MoreIds = strings.Split(args[2][1], ",")
+		comment.MoreCount = len(comment.MoreIds)
+		if countNode, ok := moreNode.FindChild("span", "gray"); ok {
+			if matches := moreCountRegex.FindStringSubmatch(countNode.Text()); len(matches) == 2 {
+				comment.MoreCount, _ = strconv.Atoi(matches[1])
+			}
+		}
+
+		return comment, true
+	}
+
+	if deepThreadNode, ok := node.FindDescendant("span", "deepthread"); ok {
+		if linkNode, ok := deepThreadNode.FindChild("a"); ok {
+			comment.MoreUrl = linkNode.GetAttr("href")
+			return comment, comment.MoreUrl != ""
+		}
+	}
+
+	return comment, false
+}
+
 func (p OldRedditCommentsParser) parseCommentNode(node common.HtmlNode, depth int) model.Comment {
 	var comment model.Comment
 	comment.Depth = depth
@@ -94,12 +160,26 @@ func (p OldRedditCommentsParser) parseCommentNode(node common.HtmlNode, depth in
 	}
 
 	if usertextNode, ok := node.FindChild("form", "usertext"); ok {
-		comment.Text = strings.TrimSpace(renderHtmlNode(usertextNode))
+		if mdNode, ok := usertextNode.FindDescendant("div", "md"); ok {
+			usertextNode = mdNode
+		}
+
+		comment.Text, comment.Html = renderMarkdown(usertextNode)
 	}
 
 	return comment
 }
 
+func (p OldRedditCommentsParser) getPostId(root common.HtmlNode) string {
+	if linkListingNode, ok := root.FindDescendant("div", "sitetable", "linklisting"); ok {
+		if thingNode, ok := linkListingNode.FindDescendant("div", "thing"); ok {
+			return strings.TrimPrefix(thingNode.GetAttr("data-fullname"), common.LinkKind+"_")
+		}
+	}
+
+	return ""
+}
+
 func (p OldRedditCommentsParser) getTitle(root common.HtmlNode) string {
 	for n := range root.FindDescendants("meta") {
 		if n.GetAttr("property") == "og:title" {
@@ -110,19 +190,18 @@ func (p OldRedditCommentsParser) getTitle(root common.HtmlNode) string {
 	return ""
 }
 
-func (p OldRedditCommentsParser) getPostContent(root common.HtmlNode) (content, url string) {
+func (p OldRedditCommentsParser) getPostContent(root common.HtmlNode) (content, source, url string) {
 	if linkListingNode, ok := root.FindDescendant("div", "sitetable", "linklisting"); ok {
 		// self post
 		if mdNode, ok := linkListingNode.FindDescendant("div", "md"); ok {
-			postText := renderHtmlNode(mdNode)
+			postText, source := renderMarkdown(mdNode)
 
 			// skip alb.reddit.com urls
 			if strings.Contains(postText, "alb.reddit.com") {
-				return "", ""
+				return "", "", ""
 			}
 
-			content = postTextTrimRegex.ReplaceAllString(postText, "\n\n")
-			return content, ""
+			return postText + "\n\n", source, ""
 		}
 	}
 
@@ -133,16 +212,16 @@ func (p OldRedditCommentsParser) getPostContent(root common.HtmlNode) (content,
 
 			// skip alb.reddit.com urls
 			if strings.Contains(url, "alb.reddit.com") {
-				return "", ""
+				return "", "", ""
 			}
 
 			content := fmt.Sprintf("%s\n\n", common.HyperLinkStyle.Render(url))
-			return content, url
+			return content, "", url
 
 		}
 	}
 
-	return "", ""
+	return "", "", ""
 }
 
 func (p OldRedditCommentsParser) getPostAuthor(root common.HtmlNode) string {
@@ -196,7 +275,16 @@ func (p OldRedditCommentsParser) getPostPoints(root common.HtmlNode) string {
 
 type RedlibCommentsParser struct{}
 
-func (p RedlibCommentsParser) ParseComments(root common.HtmlNode, url string) model.Comments {
+func (p RedlibCommentsParser) ParseComments(body io.Reader, url string) (model.Comments, error) {
+	root, err := parseHtml(body)
+	if err != nil {
+		return model.Comments{}, err
+	}
+
+	return p.parseComments(root, url), nil
+}
+
+func (p RedlibCommentsParser) parseComments(root common.HtmlNode, url string) model.Comments {
 	var (
 		commentsData model.Comments
 		commentsList []model.Comment
@@ -217,12 +305,13 @@ func (p RedlibCommentsParser) ParseComments(root common.HtmlNode, url string) mo
 	commentsData.PostPoints = p.getPostPoints(mainNode)
 	commentsData.Comments = p.parseCommentsList(mainNode, 0, commentsList)
 
-	postText, postUrl := p.getPostContent(mainNode)
+	postText, postHtml, postUrl := p.getPostContent(mainNode)
 	if postUrl == "" {
 		// Self post
 		postUrl = url
 	}
 	commentsData.PostText = postText
+	commentsData.PostHtml = postHtml
 	commentsData.PostUrl = postUrl
 
 	return commentsData
@@ -284,13 +373,12 @@ func (p RedlibCommentsParser) getPostPoints(root common.HtmlNode) string {
 	return strings.TrimSpace(pointsNode.Text())
 }
 
-func (p RedlibCommentsParser) getPostContent(root common.HtmlNode) (content, url string) {
+func (p RedlibCommentsParser) getPostContent(root common.HtmlNode) (content, source, url string) {
 	// self post
 	if postBodyNode, ok := root.FindDescendant("div", "post_body"); ok {
 		if mdNode, ok := postBodyNode.FindDescendant("div", "md"); ok {
-			postText := renderHtmlNode(mdNode)
-			content = postTextTrimRegex.ReplaceAllString(postText, "\n\n")
-			return content, ""
+			postText, source := renderMarkdown(mdNode)
+			return postText + "\n\n", source, ""
 		}
 	}
 
@@ -299,42 +387,71 @@ func (p RedlibCommentsParser) getPostContent(root common.HtmlNode) (content, url
 		if linkNode.GetAttr("id") == "post_url" {
 			url = linkNode.GetAttr("href")
 			content := fmt.Sprintf("%s\n\n", common.HyperLinkStyle.Render(url))
-			return content, url
+			return content, "", url
 		}
 	}
 
-	return "", ""
+	return "", "", ""
 }
 
+// Redlib wraps each top level comment in a div.thread, with replies nested in the blockquote.replies of their parent
 func (p RedlibCommentsParser) parseCommentsList(root common.HtmlNode, depth int, comments []model.Comment) []model.Comment {
-	for threadNode := range root.FindDescendants("div", "thread") {
-		comments = p.parseThread(threadNode, depth, comments)
+	for c := range root.ChildNodes() {
+		node := common.HtmlNode{Node: c}
+		if node.NodeEquals("div", "thread") {
+			for commentNode := range node.FindChildren("div", "comment") {
+				comments = p.parseThread(commentNode, depth, comments)
+			}
+		} else if !node.NodeEquals("div", "comment") {
+			// Threads never contain other threads, so only look for them outside of comments
+			comments = p.parseCommentsList(node, depth, comments)
+		}
 	}
 
 	return comments
 }
 
-func (p RedlibCommentsParser) parseThread(root common.HtmlNode, depth int, comments []model.Comment) []model.Comment {
-	commentNode, ok := root.FindChild("div", "comment")
+// Add the comment followed by each of its replies, in the order redlib renders them
+func (p RedlibCommentsParser) parseThread(commentNode common.HtmlNode, depth int, comments []model.Comment) []model.Comment {
+	comment := p.parseCommentNode(commentNode, depth)
+	comment.Id = commentNode.Id()
+	if model.IsDeletedComment(comment.Author, comment.Text) {
+		comment = comment.AsDeleted()
+	}
+	comments = append(comments, comment)
+
+	rightNode, ok := commentNode.FindChild("details", "comment_right")
 	if !ok {
 		return comments
 	}
 
-	comment := p.parseCommentNode(commentNode, depth)
-	comments = append(comments, comment)
+	repliesNode, ok := rightNode.FindChild("blockquote", "replies")
+	if !ok {
+		return comments
+	}
 
-	if n, ok := commentNode.FindDescendant("blockquote", "replies"); ok {
-		comments = p.parseThread(n, depth+1, comments)
+	for c := range repliesNode.ChildNodes() {
+		node := common.HtmlNode{Node: c}
+		if node.NodeEquals("div", "comment") {
+			comments = p.parseThread(node, depth+1, comments)
+		} else if node.NodeEquals("a", "deeper_replies") {
+			// Replies nested too deeply are replaced with a link to continue the thread
+			comments = append(comments, model.Comment{
+				Depth:   depth + 1,
+				MoreUrl: node.GetAttr("href"),
+			})
+		}
 	}
 
 	return comments
 }
 
+// Only look at the comment's own children, its replies are nested further down in the same node
 func (p RedlibCommentsParser) parseCommentNode(node common.HtmlNode, depth int) model.Comment {
 	var comment model.Comment
 	comment.Depth = depth
 
-	if leftNode, ok := node.FindDescendant("div", "comment_left"); ok {
+	if leftNode, ok := node.FindChild("div", "comment_left"); ok {
 		if scoreNode, ok := leftNode.FindChild("p", "comment_score"); ok {
 			points := "1 point"
 			if scoreNode.GetAttr("title") != "Hidden" {
@@ -344,54 +461,53 @@ func (p RedlibCommentsParser) parseCommentNode(node common.HtmlNode, depth int)
 		}
 	}
 
-	if rightNode, ok := node.FindDescendant("details", "comment_right"); ok {
-		if authorNode, ok := rightNode.FindDescendant("a", "comment_author"); ok {
-			author := authorNode.Text()
-			if len(author) > 2 && author[:2] == "u/" {
-				author = author[2:]
+	if rightNode, ok := node.FindChild("details", "comment_right"); ok {
+		if dataNode, ok := rightNode.FindChild("summary", "comment_data"); ok {
+			// Deleted authors have no user page to link to, so redlib shows them in a span
+			authorNode, ok := dataNode.FindDescendant("a", "comment_author")
+			if !ok {
+				authorNode, ok = dataNode.FindDescendant("span", "comment_author")
 			}
-			comment.Author = author
-		}
 
-		if timestampNode, ok := rightNode.FindDescendant("a", "created"); ok {
-			comment.Timestamp = timestampNode.Text()
+			if ok {
+				author := authorNode.Text()
+				if len(author) > 2 && author[:2] == "u/" {
+					author = author[2:]
+				}
+				comment.Author = author
+			}
+
+			if timestampNode, ok := dataNode.FindDescendant("a", "created"); ok {
+				comment.Timestamp = timestampNode.Text()
+			}
 		}
 
-		if commentBodyNode, ok := node.FindDescendant("div", "md"); ok {
-			commentText := strings.TrimSpace(renderHtmlNode(commentBodyNode))
-			comment.Text = postTextTrimRegex.ReplaceAllString(commentText, "\n\n")
+		if commentBodyNode, ok := rightNode.FindChild("div", "comment_body"); ok {
+			if mdNode, ok := commentBodyNode.FindDescendant("div", "md"); ok {
+				commentBodyNode = mdNode
+			}
+
+			comment.Text, comment.Html = renderMarkdown(commentBodyNode)
 		}
 	}
 
 	return comment
 }
 
-func renderHtmlNode(node common.HtmlNode) string {
-	var content strings.Builder
-	for child := range node.ChildNodes() {
-		cNode := common.HtmlNode{Node: child}
+func parseHtml(body io.Reader) (common.HtmlNode, error) {
+	timer := utils.NewTimer("parsing comments html")
+	defer timer.StopAndLog()
 
-		var nodeResults strings.Builder
-		renderHtmlNodeHelper(cNode, &nodeResults)
-		content.WriteString(nodeResults.String())
-		content.WriteString("\n")
+	doc, err := html.Parse(body)
+	if err != nil {
+		return common.HtmlNode{}, err
 	}
 
-	return content.String()
+	return common.HtmlNode{Node: doc}, nil
 }
 
-func renderHtmlNodeHelper(node common.HtmlNode, results *strings.Builder) {
-	if node.Type == html.TextNode {
-		results.WriteString(node.Data)
-	} else if node.Tag() == "a" {
-		results.WriteString(common.RenderAnchor(node))
-		return
-	} else if node.Tag() == "li" {
-		results.WriteString(node.Text())
-		return
-	}
-
-	for child := range node.ChildNodes() {
-		renderHtmlNodeHelper(common.HtmlNode{Node: child}, results)
-	}
+// Render the markdown html of a post or comment without wrapping it. The html is kept so the comments
+// page can render it again at the width of the terminal
+func renderMarkdown(node common.HtmlNode) (text, source string) {
+	return render.Renderer{}.Render(node), render.Source(node)
 }
@@ -0,0 +1,70 @@
+package comments
+
+import (
+	"context"
+	"reddittui/client/cache"
+	"reddittui/config"
+	"reddittui/model"
+	"testing"
+	"time"
+)
+
+func TestGetDefaultSort(t *testing.T) {
+	configuration := config.NewConfig()
+	configuration.Comments.DefaultSort = "best"
+	configuration.Comments.SubredditSorts = map[string]string{
+		"AskHistorians": "top",
+		"r/golang":      "new",
+		"dogs":          "Q&A",
+	}
+
+	client := NewRedditCommentsClient(testBaseUrl, nil, nil, configuration)
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{testBaseUrl + "/r/askhistorians/comments/abc/title/", "top"},
+		{testBaseUrl + "/r/golang/comments/abc/title/", "new"},
+		{testBaseUrl + "/r/dogs/comments/abc/title/", "qa"},
+		{testBaseUrl + "/r/cats/comments/abc/title/", "confidence"},
+		{testBaseUrl + "/comments/abc", "confidence"},
+	}
+
+	for _, tt := range tests {
+		got := client.GetDefaultSort(tt.url)
+		if got != tt.want {
+			t.Errorf("got %s, want %s with input %s", got, tt.want, tt.url)
+		}
+	}
+}
+
+func TestCacheComments(t *testing.T) {
+	configuration := config.NewConfig()
+	commentsCache := cache.NewFileCommentsCache(testBaseUrl, t.TempDir())
+	client := NewRedditCommentsClient(testBaseUrl, nil, commentsCache, configuration)
+
+	url := testBaseUrl + "/r/golang/comments/abc/title/"
+	comments := model.Comments{
+		PostId: "abc",
+		Sort:   "new",
+		Expiry: time.Now().Add(time.Hour),
+		Comments: []model.Comment{
+			{Id: "c1", Author: "gopher", Text: "top level"},
+			{Id: "c2", Author: "gopher", Text: "loaded reply", Depth: 1},
+		},
+	}
+
+	if err := client.CacheComments(context.Background(), url, comments); err != nil {
+		t.Fatalf("could not cache comments: %v", err)
+	}
+
+	// Loaded replies are served from the cache instead of the server
+	cached, err := client.GetComments(context.Background(), url, "new")
+	if err != nil {
+		t.Fatalf("could not get cached comments: %v", err)
+	}
+
+	assertVal("comments", len(comments.Comments), len(cached.Comments), t)
+	assertVal("reply", "loaded reply", cached.Comments[1].Text, t)
+}
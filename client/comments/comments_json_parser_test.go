@@ -0,0 +1,115 @@
+package comments
+
+import (
+	"os"
+	"reddittui/model"
+	"strings"
+	"testing"
+)
+
+const (
+	testBaseUrl     = "https://old.reddit.com"
+	testCommentsUrl = testBaseUrl + "/r/dogs/comments/1jgxswb/dog_becoming_cuddlier_as_a_senior/"
+)
+
+func TestJsonCommentsParserPost(t *testing.T) {
+	comments := parseJsonFixture(t, "testdata/comments.json")
+
+	assertVal("PostId", "1jgxswb", comments.PostId, t)
+	assertVal("PostTitle", "Dog becoming cuddlier as a senior", comments.PostTitle, t)
+	assertVal("PostAuthor", "doglover", comments.PostAuthor, t)
+	assertVal("Subreddit", "dogs", comments.Subreddit, t)
+	assertVal("PostPoints", "1234", comments.PostPoints, t)
+	assertVal("PostUrl", testCommentsUrl, comments.PostUrl, t)
+	assertVal("PostText", "My dog is 12 now.\n\n", comments.PostText, t)
+}
+
+func TestJsonCommentsParserReplyTree(t *testing.T) {
+	comments := parseJsonFixture(t, "testdata/comments.json")
+
+	tests := []struct {
+		id     string
+		author string
+		text   string
+		points string
+		depth  int
+	}{
+		{"c1", "first", "Top level comment", "10 points", 0},
+		{"c2", "second", "First reply", "1 point", 1},
+		{"c3", "third", "Nested reply", "-2 points", 2},
+		{"c4", "fourth", "Second reply", "[score hidden]", 1},
+		{"c5", "fifth", "Another top level comment", "5 points", 0},
+	}
+
+	if len(comments.Comments) != len(tests) {
+		t.Fatalf("expected %d comments but got %d", len(tests), len(comments.Comments))
+	}
+
+	for i, tt := range tests {
+		got := comments.Comments[i]
+		assertVal("Id", tt.id, got.Id, t)
+		assertVal("Author", tt.author, got.Author, t)
+		assertVal("Text", tt.text, got.Text, t)
+		assertVal("Points", tt.points, got.Points, t)
+		assertVal("Depth", tt.depth, got.Depth, t)
+	}
+}
+
+func parseJsonFixture(t *testing.T, path string) model.Comments {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("could not open fixture %s: %v", path, err)
+	}
+	defer file.Close()
+
+	comments, err := JsonCommentsParser{}.ParseComments(file, testCommentsUrl)
+	if err != nil {
+		t.Fatalf("could not parse fixture %s: %v", path, err)
+	}
+
+	return comments
+}
+
+func assertVal[K comparable](context string, expected, got K, t *testing.T) {
+	if expected != got {
+		t.Errorf("assertion failed %s: for expected %v but got %v", context, expected, got)
+	}
+}
+
+func TestJsonCommentsParserMoreChildren(t *testing.T) {
+	body := `{"json": {"errors": [], "data": {"things": [
+		{"kind": "t1", "data": {"id": "m1", "name": "t1_m1", "parent_id": "t3_1jgxswb", "author": "one", "body_html": "&lt;div class=\"md\"&gt;&lt;p&gt;One&lt;/p&gt;&lt;/div&gt;", "score": 3}},
+		{"kind": "t1", "data": {"id": "m3", "name": "t1_m3", "parent_id": "t1_m2", "author": "three", "body_html": "&lt;div class=\"md\"&gt;&lt;p&gt;Three&lt;/p&gt;&lt;/div&gt;", "score": 1}},
+		{"kind": "t1", "data": {"id": "m2", "name": "t1_m2", "parent_id": "t1_m1", "author": "two", "body_html": "&lt;div class=\"md\"&gt;&lt;p&gt;Two&lt;/p&gt;&lt;/div&gt;", "score": 2}},
+		{"kind": "more", "data": {"id": "m4", "parent_id": "t1_m1", "count": 7, "children": ["m4", "m5"]}}
+	]}}}`
+
+	comments, err := JsonCommentsParser{}.ParseMoreChildren(strings.NewReader(body), 2)
+	if err != nil {
+		t.Fatalf("could not parse more children: %v", err)
+	}
+
+	tests := []struct {
+		author string
+		depth  int
+		more   bool
+	}{
+		{"one", 2, false},
+		{"two", 3, false},
+		{"three", 4, false},
+		{"", 3, true},
+	}
+
+	if len(comments) != len(tests) {
+		t.Fatalf("expected %d comments but got %d", len(tests), len(comments))
+	}
+
+	for i, tt := range tests {
+		got := comments[i]
+		assertVal("Author", tt.author, got.Author, t)
+		assertVal("Depth", tt.depth, got.Depth, t)
+		assertVal("IsMore", tt.more, got.IsMore(), t)
+	}
+
+	assertVal("MoreDescription", "load more comments (7 replies)", comments[3].MoreDescription(), t)
+}
@@ -0,0 +1,178 @@
+package comments
+
+import (
+	"os"
+	"reddittui/model"
+	"strings"
+	"testing"
+)
+
+const testDeletedCommentsPage = `<html><body>
+<div class="sitetable nestedlisting">
+	<div class="thing id-t1_c1 noncollapsed comment" data-fullname="t1_c1">
+		<div class="entry unvoted">
+			<p class="tagline"><a class="author">first</a> <span class="score likes">10 points</span> <time class="live-timestamp">3 hours ago</time></p>
+			<form class="usertext"><div class="usertext-body"><div class="md"><p>Top level comment</p></div></div></form>
+		</div>
+		<div class="child"><div class="sitetable listing">
+			<div class="thing id-t1_c2 noncollapsed deleted comment" data-fullname="t1_c2">
+				<div class="entry unvoted">
+					<p class="tagline"><em>[deleted]</em> <time class="live-timestamp">2 hours ago</time></p>
+					<form class="usertext"><div class="usertext-body"><div class="md"><p>[removed]</p></div></div></form>
+				</div>
+				<div class="child"><div class="sitetable listing">
+					<div class="thing id-t1_c3 noncollapsed comment" data-fullname="t1_c3">
+						<div class="entry unvoted">
+							<p class="tagline"><a class="author">third</a> <span class="score likes">4 points</span> <time class="live-timestamp">1 hour ago</time></p>
+							<form class="usertext"><div class="usertext-body"><div class="md"><p>Reply to a removed comment</p></div></div></form>
+						</div>
+					</div>
+				</div></div>
+			</div>
+		</div></div>
+	</div>
+	<div class="thing id-t1_c4 noncollapsed deleted comment" data-fullname="t1_c4">
+		<div class="child"><div class="sitetable listing">
+			<div class="thing id-t1_c5 noncollapsed comment" data-fullname="t1_c5">
+				<div class="entry unvoted">
+					<p class="tagline"><a class="author">fifth</a> <span class="score likes">1 point</span> <time class="live-timestamp">just now</time></p>
+					<form class="usertext"><div class="usertext-body"><div class="md"><p>Reply to a deleted comment</p></div></div></form>
+				</div>
+			</div>
+		</div></div>
+	</div>
+</div>
+</body></html>`
+
+func TestOldRedditDeletedComments(t *testing.T) {
+	comments, err := OldRedditCommentsParser{}.ParseComments(strings.NewReader(testDeletedCommentsPage), testCommentsUrl)
+	if err != nil {
+		t.Fatalf("could not parse comments: %v", err)
+	}
+
+	tests := []struct {
+		id      string
+		author  string
+		text    string
+		depth   int
+		deleted bool
+	}{
+		{"c1", "first", "Top level comment", 0, false},
+		{"c2", "[deleted]", "[removed]", 1, true},
+		{"c3", "third", "Reply to a removed comment", 2, false},
+		{"c4", "[deleted]", "[deleted]", 0, true},
+		{"c5", "fifth", "Reply to a deleted comment", 1, false},
+	}
+
+	if len(comments.Comments) != len(tests) {
+		t.Fatalf("got %d comments, want %d", len(comments.Comments), len(tests))
+	}
+
+	for i, tt := range tests {
+		comment := comments.Comments[i]
+		assertVal("Id", tt.id, comment.Id, t)
+		assertVal(tt.id+" Author", tt.author, comment.Author, t)
+		assertVal(tt.id+" Text", tt.text, comment.Text, t)
+		assertVal(tt.id+" Depth", tt.depth, comment.Depth, t)
+		assertVal(tt.id+" Deleted", tt.deleted, comment.Deleted, t)
+	}
+
+	// Placeholders cannot be voted on or replied to
+	assertVal("placeholder fullname", "", comments.Comments[1].Fullname(), t)
+	assertVal("placeholder points", "", comments.Comments[1].Points, t)
+	assertVal("placeholder timestamp", "2 hours ago", comments.Comments[1].Timestamp, t)
+}
+
+func TestRedlibCommentsParserPost(t *testing.T) {
+	comments := parseRedlibFixture(t, "testdata/redlib_comments.html")
+
+	assertVal("PostTitle", "Dog becoming cuddlier as a senior", comments.PostTitle, t)
+	assertVal("PostAuthor", "doglover", comments.PostAuthor, t)
+	assertVal("Subreddit", "r/dogs", comments.Subreddit, t)
+	assertVal("PostTimestamp", "5h ago", comments.PostTimestamp, t)
+	assertVal("PostUrl", testCommentsUrl, comments.PostUrl, t)
+}
+
+func TestRedlibCommentsParserReplyTree(t *testing.T) {
+	comments := parseRedlibFixture(t, "testdata/redlib_comments.html")
+
+	tests := []struct {
+		id      string
+		author  string
+		text    string
+		points  string
+		depth   int
+		moreUrl string
+	}{
+		{"mj1qz0p", "AutoModerator", "Please remember to follow the rules of r/dogs.", "1 point", 0, ""},
+		{"mj1r4kd", "alice_k9", "Mine did the same around 10.", "120 points", 0, ""},
+		{"mj1s9wv", "doglover", "Glad to hear it is not just mine.", "45 points", 1, ""},
+		{"mj1uk3f", "bobsnoot", "Old dogs know what matters.", "12 points", 2, ""},
+		{"mj1vb7n", "carol_pets", "Same with my cat.\n\nShe sleeps on my feet now.", "8 points", 2, ""},
+		{"mj1t2ag", "davethedog", "Enjoy every minute of it.", "30 points", 1, ""},
+		{"mj1x8pe", "erin_walks", "Has anything else changed?", "15 points", 0, ""},
+		{"mj1zq4c", "doglover", "│ Has anything else changed?\n\nHe sleeps a lot more.", "9 points", 1, ""},
+		{"", "", "", "", 2, "/r/dogs/comments/1jgxswb/dog_becoming_cuddlier_as_a_senior/mj1zq4c"},
+		{"mj22c1x", "frank_fetch", "Cute!", "1 point", 0, ""},
+	}
+
+	if len(comments.Comments) != len(tests) {
+		t.Fatalf("got %d comments, want %d", len(comments.Comments), len(tests))
+	}
+
+	for i, tt := range tests {
+		comment := comments.Comments[i]
+		assertVal("Id", tt.id, comment.Id, t)
+		assertVal(tt.id+" Author", tt.author, comment.Author, t)
+		assertVal(tt.id+" Text", tt.text, comment.Text, t)
+		assertVal(tt.id+" Points", tt.points, comment.Points, t)
+		assertVal(tt.id+" Depth", tt.depth, comment.Depth, t)
+		assertVal(tt.id+" MoreUrl", tt.moreUrl, comment.MoreUrl, t)
+	}
+}
+
+func TestRedlibCommentsParserSingleThread(t *testing.T) {
+	comments := parseRedlibFixture(t, "testdata/redlib_thread.html")
+
+	tests := []struct {
+		id      string
+		author  string
+		text    string
+		depth   int
+		deleted bool
+	}{
+		{"mj1wr3t", "[deleted]", "[removed]", 0, true},
+		{"mj22x8b", "grace_h", "What did they say?", 1, false},
+		{"mj23c9w", "heidi_paws", "Something about treats.", 1, false},
+		{"mj24nfe", "[deleted]", "[deleted]", 2, true},
+		{"mj25ht0", "ivan_barks", "Treats are always the answer.", 3, false},
+	}
+
+	if len(comments.Comments) != len(tests) {
+		t.Fatalf("got %d comments, want %d", len(comments.Comments), len(tests))
+	}
+
+	for i, tt := range tests {
+		comment := comments.Comments[i]
+		assertVal("Id", tt.id, comment.Id, t)
+		assertVal(tt.id+" Author", tt.author, comment.Author, t)
+		assertVal(tt.id+" Text", tt.text, comment.Text, t)
+		assertVal(tt.id+" Depth", tt.depth, comment.Depth, t)
+		assertVal(tt.id+" Deleted", tt.deleted, comment.Deleted, t)
+	}
+}
+
+func parseRedlibFixture(t *testing.T, path string) model.Comments {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("could not open fixture %s: %v", path, err)
+	}
+	defer file.Close()
+
+	comments, err := RedlibCommentsParser{}.ParseComments(file, testCommentsUrl)
+	if err != nil {
+		t.Fatalf("could not parse fixture %s: %v", path, err)
+	}
+
+	return comments
+}
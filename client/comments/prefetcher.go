@@ -0,0 +1,91 @@
+package comments
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Pending prefetches beyond this are dropped, the cursor has usually moved on before they would run
+const maxPrefetchQueue = 32
+
+type prefetchRequest struct {
+	url        string
+	generation int
+}
+
+// Pool of workers loading comments into the cache in the background. Every call to Prefetch replaces
+// the urls still waiting in the queue, so only posts near the cursor are loaded. The number of workers
+// limits how many prefetches run at once across every page
+type Prefetcher struct {
+	fetch func(ctx context.Context, url string) error
+	queue chan prefetchRequest
+
+	mu         *sync.Mutex
+	generation int
+	seen       map[string]bool
+}
+
+func NewPrefetcher(fetch func(ctx context.Context, url string) error, concurrency int) *Prefetcher {
+	p := &Prefetcher{
+		fetch: fetch,
+		queue: make(chan prefetchRequest, maxPrefetchQueue),
+		mu:    &sync.Mutex{},
+		seen:  make(map[string]bool),
+	}
+
+	for range concurrency {
+		go p.work()
+	}
+
+	return p
+}
+
+// Queue urls for prefetching in order, skipping urls that were already prefetched
+func (p *Prefetcher) Prefetch(urls []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.generation++
+	for _, url := range urls {
+		if len(url) == 0 || p.seen[url] {
+			continue
+		}
+
+		select {
+		case p.queue <- prefetchRequest{url, p.generation}:
+		default:
+			return
+		}
+	}
+}
+
+func (p *Prefetcher) work() {
+	for request := range p.queue {
+		if !p.start(request) {
+			continue
+		}
+
+		if err := p.fetch(context.Background(), request.url); err != nil {
+			slog.Debug("Could not prefetch comments", "url", request.url, "error", err)
+
+			// Allow the url to be prefetched again the next time the cursor is near it
+			p.mu.Lock()
+			delete(p.seen, request.url)
+			p.mu.Unlock()
+		}
+	}
+}
+
+// Skip requests queued before the latest call to Prefetch and urls another worker already loaded
+func (p *Prefetcher) start(request prefetchRequest) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if request.generation != p.generation || p.seen[request.url] {
+		return false
+	}
+
+	p.seen[request.url] = true
+	return true
+}
@@ -0,0 +1,63 @@
+package comments
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func waitForFetch(fetched chan string, t *testing.T) string {
+	select {
+	case url := <-fetched:
+		return url
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for prefetch")
+		return ""
+	}
+}
+
+func TestPrefetcher(t *testing.T) {
+	fetched := make(chan string)
+	failures := map[string]bool{"failed": true}
+
+	prefetcher := NewPrefetcher(func(ctx context.Context, url string) error {
+		fetched <- url
+		if failures[url] {
+			delete(failures, url)
+			return errors.New("prefetch failed")
+		}
+
+		return nil
+	}, 1)
+
+	prefetcher.Prefetch([]string{"first", "", "failed"})
+	assertVal("first prefetch", "first", waitForFetch(fetched, t), t)
+	assertVal("second prefetch", "failed", waitForFetch(fetched, t), t)
+
+	// Wait for the worker to forget the failed url
+	for deadline := time.Now().Add(time.Second); prefetcher.isSeen("failed"); {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for failed prefetch")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Urls that were already prefetched are skipped, failed urls are tried again
+	prefetcher.Prefetch([]string{"first", "failed", "second"})
+	assertVal("retried prefetch", "failed", waitForFetch(fetched, t), t)
+	assertVal("new prefetch", "second", waitForFetch(fetched, t), t)
+
+	prefetcher.Prefetch([]string{"first", "failed", "second"})
+	select {
+	case url := <-fetched:
+		t.Errorf("expected no prefetches but got %s", url)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func (p *Prefetcher) isSeen(url string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.seen[url]
+}
@@ -0,0 +1,137 @@
+package common
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const CookiesFileName = "cookies.json"
+
+type savedCookie struct {
+	Url    string       `json:"url"`
+	Cookie *http.Cookie `json:"cookie"`
+}
+
+// Cookie jar that saves cookies to a file whenever the server sets them, and loads them back on the next
+// run. Only cookies with an expiry are saved, session cookies end when reddittui exits
+type PersistentJar struct {
+	jar  *cookiejar.Jar
+	path string
+	now  func() time.Time
+
+	mu    *sync.Mutex
+	saved map[string]savedCookie
+}
+
+func NewPersistentJar(path string) (*PersistentJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PersistentJar{
+		jar:   jar,
+		path:  path,
+		now:   time.Now,
+		mu:    &sync.Mutex{},
+		saved: make(map[string]savedCookie),
+	}
+
+	p.load()
+	return p, nil
+}
+
+func (p *PersistentJar) Cookies(u *url.URL) []*http.Cookie {
+	return p.jar.Cookies(u)
+}
+
+func (p *PersistentJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	p.jar.SetCookies(u, cookies)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	for _, cookie := range cookies {
+		saved := *cookie
+		if saved.MaxAge > 0 {
+			saved.Expires = now.Add(time.Duration(saved.MaxAge) * time.Second)
+		}
+		saved.MaxAge = 0
+
+		key := cookieKey(u, cookie)
+		if cookie.MaxAge < 0 || saved.Expires.IsZero() || !saved.Expires.After(now) {
+			// Deleted or session cookie
+			delete(p.saved, key)
+			continue
+		}
+
+		p.saved[key] = savedCookie{u.String(), &saved}
+	}
+
+	if err := p.save(); err != nil {
+		slog.Warn("Could not save cookies", "path", p.path, "error", err)
+	}
+}
+
+func (p *PersistentJar) load() {
+	data, err := os.ReadFile(p.path)
+	if os.IsNotExist(err) {
+		return
+	} else if err != nil {
+		slog.Warn("Could not read cookies", "path", p.path, "error", err)
+		return
+	}
+
+	var saved []savedCookie
+	if err := json.Unmarshal(data, &saved); err != nil {
+		slog.Warn("Could not decode cookies", "path", p.path, "error", err)
+		return
+	}
+
+	now := p.now()
+	for _, entry := range saved {
+		u, err := url.Parse(entry.Url)
+		if err != nil || entry.Cookie == nil || !entry.Cookie.Expires.After(now) {
+			continue
+		}
+
+		p.jar.SetCookies(u, []*http.Cookie{entry.Cookie})
+		p.saved[cookieKey(u, entry.Cookie)] = entry
+	}
+}
+
+func (p *PersistentJar) save() error {
+	var saved []savedCookie
+	for _, entry := range p.saved {
+		saved = append(saved, entry)
+	}
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p.path), 0750); err != nil {
+		return err
+	}
+
+	// Cookies may hold session tokens, keep them private
+	return os.WriteFile(p.path, data, 0600)
+}
+
+func cookieKey(u *url.URL, cookie *http.Cookie) string {
+	domain := cookie.Domain
+	if len(domain) == 0 {
+		domain = u.Hostname()
+	}
+
+	return domain + cookie.Path + ";" + cookie.Name
+}
@@ -0,0 +1,49 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestPersistentJar(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/settings" {
+			http.SetCookie(w, &http.Cookie{Name: "theme", Value: "dark", Path: "/", MaxAge: 3600})
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+			return
+		}
+
+		for _, cookie := range r.Cookies() {
+			w.Header().Add("X-Cookie", cookie.Name+"="+cookie.Value)
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), CookiesFileName)
+	jar, err := NewPersistentJar(path)
+	if err != nil {
+		t.Fatalf("unexpected error creating cookie jar: %v", err)
+	}
+
+	client := &http.Client{Jar: jar}
+	if _, err := client.Get(server.URL + "/settings"); err != nil {
+		t.Fatalf("unexpected error setting cookies: %v", err)
+	}
+
+	res, _ := client.Get(server.URL)
+	assertVal("cookies in the same run", 2, len(res.Header.Values("X-Cookie")), t)
+
+	// Only cookies with an expiry are loaded on the next run
+	jar, err = NewPersistentJar(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading cookie jar: %v", err)
+	}
+
+	client = &http.Client{Jar: jar}
+	res, _ = client.Get(server.URL)
+	cookies := res.Header.Values("X-Cookie")
+	assertVal("cookies in the next run", 1, len(cookies), t)
+	assertVal("saved cookie", "theme=dark", cookies[0], t)
+}
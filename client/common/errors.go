@@ -1,6 +1,16 @@
 package common
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
 
 var (
 	ErrCacheEntryExpired     = errors.New("entry is expired")
@@ -9,4 +19,101 @@ var (
 	ErrCannotOpenCacheFile   = errors.New("cannot open cache file")
 	ErrCannotEncodeCacheFile = errors.New("cannot encode cache file")
 	ErrCannotDecodeCacheFile = errors.New("cannot decode cache file")
+	ErrRateLimited           = errors.New("rate limited")
+	ErrForbidden             = errors.New("forbidden")
+	ErrQuarantined           = errors.New("quarantined")
+	ErrBanned                = errors.New("banned")
+	ErrServerError           = errors.New("server error")
+	ErrTimeout               = errors.New("timeout")
+	ErrOver18                = errors.New("over 18 confirmation required")
 )
+
+// Only the start of error pages is searched for the reason a request failed
+const maxErrorBodySize = 64 * 1024
+
+// Unsuccessful response from the server. Use errors.Is with one of the errors above to check the cause
+type ResponseError struct {
+	Err        error
+	StatusCode int
+	Url        string
+	RetryAfter time.Duration
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%v: status %d from %s", e.Err, e.StatusCode, e.Url)
+}
+
+func (e *ResponseError) Unwrap() error {
+	return e.Err
+}
+
+// Convert an unsuccessful response into a ResponseError. Reddit uses the same status codes for
+// several failures, i.e. private and quarantined subreddits are both 403s, so the body is searched
+// for the reason
+func NewResponseError(res *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+	reason := strings.ToLower(string(body))
+
+	responseErr := &ResponseError{
+		StatusCode: res.StatusCode,
+		Url:        res.Request.URL.String(),
+	}
+
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests:
+		responseErr.Err = ErrRateLimited
+		responseErr.RetryAfter = ParseRetryAfter(res.Header.Get("Retry-After"), time.Now())
+	case res.StatusCode >= http.StatusInternalServerError:
+		responseErr.Err = ErrServerError
+	case strings.Contains(reason, "nsfw_landing"):
+		// Redlib refuses to show over 18 subreddits unless the show_nsfw preference is set
+		responseErr.Err = ErrOver18
+	case strings.Contains(reason, "quarantine"):
+		responseErr.Err = ErrQuarantined
+	case strings.Contains(reason, "banned"):
+		responseErr.Err = ErrBanned
+	case res.StatusCode == http.StatusForbidden || res.StatusCode == http.StatusUnauthorized:
+		responseErr.Err = ErrForbidden
+	default:
+		// Treat all other non-200s as 404s
+		responseErr.Err = ErrNotFound
+	}
+
+	return responseErr
+}
+
+// Mark timeouts and unreachable servers returned by the http client so they can be retried and
+// described to the user
+func WrapRequestError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	} else if IsNetworkError(err) {
+		return fmt.Errorf("%w: %w", ErrOffline, err)
+	}
+
+	return err
+}
+
+// Retry-After is either a number of seconds or a date
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+
+	return 0
+}
+
+// Rate limits, server errors and timeouts usually succeed when tried again
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrServerError) || errors.Is(err, ErrTimeout)
+}
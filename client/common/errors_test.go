@@ -0,0 +1,71 @@
+package common
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewResponseError(t *testing.T) {
+	type testCase struct {
+		statusCode int
+		body       string
+		retryAfter string
+		expected   error
+		wait       time.Duration
+	}
+
+	tests := []testCase{
+		{http.StatusTooManyRequests, "", "7", ErrRateLimited, 7 * time.Second},
+		{http.StatusServiceUnavailable, "", "", ErrServerError, 0},
+		{http.StatusForbidden, "<p>this community has been quarantined</p>", "", ErrQuarantined, 0},
+		{http.StatusNotFound, "<p>this community has been banned</p>", "", ErrBanned, 0},
+		{http.StatusForbidden, "<p>this is a private community</p>", "", ErrForbidden, 0},
+		{http.StatusForbidden, `<div id="nsfw_landing"><h1>r/dogs is a NSFW community!</h1></div>`, "", ErrOver18, 0},
+		{http.StatusNotFound, "", "", ErrNotFound, 0},
+	}
+
+	requestUrl, _ := url.Parse("https://old.reddit.com/r/dogs")
+	for _, test := range tests {
+		res := &http.Response{
+			StatusCode: test.statusCode,
+			Header:     http.Header{"Retry-After": []string{test.retryAfter}},
+			Body:       io.NopCloser(strings.NewReader(test.body)),
+			Request:    &http.Request{URL: requestUrl},
+		}
+
+		err := NewResponseError(res)
+		assertVal("errors.Is", true, errors.Is(err, test.expected), t)
+
+		var responseErr *ResponseError
+		assertVal("errors.As", true, errors.As(err, &responseErr), t)
+		assertVal("StatusCode", test.statusCode, responseErr.StatusCode, t)
+		assertVal("RetryAfter", test.wait, responseErr.RetryAfter, t)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	type testCase struct {
+		value    string
+		expected time.Duration
+	}
+
+	tests := []testCase{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{"-5", 0},
+		{"Mon, 01 Jan 2024 12:01:00 GMT", time.Minute},
+		{"Mon, 01 Jan 2024 11:00:00 GMT", 0},
+		{"soon", 0},
+	}
+
+	for _, test := range tests {
+		assertVal(test.value, test.expected, ParseRetryAfter(test.value, now), t)
+	}
+}
@@ -0,0 +1,45 @@
+package common
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Round tripper that adds the user agent and any extra configured headers to every request, along with
+// the over 18 cookies once the user has confirmed they are over 18
+type HeaderTransport struct {
+	Transport http.RoundTripper
+	UserAgent string
+	Headers   map[string]string
+	Over18    *Over18Consent
+}
+
+// Use the default browser user agent when userAgent is empty
+func NewHeaderTransport(transport http.RoundTripper, userAgent string, headers map[string]string) HeaderTransport {
+	if userAgent = strings.TrimSpace(userAgent); len(userAgent) == 0 {
+		userAgent = UserAgentHeaderValue
+	}
+
+	return HeaderTransport{
+		Transport: transport,
+		UserAgent: userAgent,
+		Headers:   headers,
+	}
+}
+
+func (t HeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Round trippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set(UserAgentHeaderKey, t.UserAgent)
+	for key, value := range t.Headers {
+		req.Header.Set(key, value)
+	}
+
+	if t.Over18.IsConfirmed() {
+		for _, cookie := range over18Cookies {
+			req.AddCookie(cookie)
+		}
+	}
+
+	return t.Transport.RoundTrip(req)
+}
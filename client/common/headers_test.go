@@ -0,0 +1,32 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHeaderTransport(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+	}))
+	defer server.Close()
+
+	transport := NewHeaderTransport(http.DefaultTransport, "", map[string]string{"Accept-Language": "de-DE"})
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatalf("unexpected error sending request: %v", err)
+	}
+
+	assertVal("default user agent", UserAgentHeaderValue, received.Get(UserAgentHeaderKey), t)
+	assertVal("extra header", "de-DE", received.Get("Accept-Language"), t)
+	assertVal("caller's request", "", req.Header.Get("Accept-Language"), t)
+
+	transport = NewHeaderTransport(http.DefaultTransport, "reddittui/1.0", nil)
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatalf("unexpected error sending request: %v", err)
+	}
+
+	assertVal("configured user agent", "reddittui/1.0", received.Get(UserAgentHeaderKey), t)
+}
@@ -0,0 +1,236 @@
+package common
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Round tripper that sends requests for the primary server to the first healthy server in an ordered
+// list of instances. Requests are always built using the primary server's url so cache keys do not
+// depend on which instance served them. Instances that fail with connection errors, 429s or 5xxs are
+// skipped until their cooldown expires
+type InstancePool struct {
+	instances      []*url.URL
+	cooldown       time.Duration
+	attemptTimeout time.Duration
+	transport      http.RoundTripper
+	now            func() time.Time
+
+	mu             *sync.Mutex
+	active         int
+	unhealthyUntil map[string]time.Time
+}
+
+func NewInstancePool(
+	instanceUrls []string,
+	cooldown,
+	attemptTimeout time.Duration,
+	transport http.RoundTripper,
+) (*InstancePool, error) {
+	var instances []*url.URL
+	for _, instanceUrl := range instanceUrls {
+		parsed, err := url.Parse(instanceUrl)
+		if err != nil {
+			return nil, err
+		}
+
+		instances = append(instances, parsed)
+	}
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return &InstancePool{
+		instances:      instances,
+		cooldown:       cooldown,
+		attemptTimeout: attemptTimeout,
+		transport:      transport,
+		now:            time.Now,
+		mu:             &sync.Mutex{},
+		unhealthyUntil: make(map[string]time.Time),
+	}, nil
+}
+
+func (p *InstancePool) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Only requests for the primary server are spread across instances
+	if len(p.instances) == 0 || req.URL.Host != p.instances[0].Host {
+		return p.roundTripOther(req)
+	}
+
+	candidates := p.candidates()
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		// Requests with bodies that cannot be replayed only get one attempt
+		candidates = candidates[:1]
+	}
+
+	for i, index := range candidates {
+		last := i == len(candidates)-1
+		instance := p.instances[index]
+
+		attempt, cancel, err := p.newAttempt(req, instance)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := p.transport.RoundTrip(attempt)
+		if req.Context().Err() != nil {
+			// Request was cancelled by the caller, the instance is not at fault
+			cancel()
+			return res, err
+		}
+
+		if err == nil && !isUnhealthyStatus(res.StatusCode) {
+			p.markHealthy(index)
+			res.Body = cancelOnClose{res.Body, cancel}
+			return res, nil
+		}
+
+		p.markUnhealthy(index)
+		if err != nil {
+			slog.Warn("Instance request failed", "instance", instance.Host, "error", err)
+		} else {
+			slog.Warn("Instance returned an error", "instance", instance.Host, "StatusCode", res.StatusCode)
+		}
+
+		if last {
+			if res != nil {
+				res.Body = cancelOnClose{res.Body, cancel}
+			} else {
+				cancel()
+			}
+
+			return res, err
+		}
+
+		if res != nil {
+			res.Body.Close()
+		}
+		cancel()
+	}
+
+	return nil, ErrNotFound
+}
+
+func (p *InstancePool) roundTripOther(req *http.Request) (*http.Response, error) {
+	attempt, cancel, err := p.newAttempt(req, req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := p.transport.RoundTrip(attempt)
+	if err != nil {
+		cancel()
+		return res, err
+	}
+
+	res.Body = cancelOnClose{res.Body, cancel}
+	return res, nil
+}
+
+// Host of the instance that served the last successful request
+func (p *InstancePool) Active() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.instances) == 0 {
+		return ""
+	}
+
+	return p.instances[p.active].Host
+}
+
+func (p *InstancePool) Size() int {
+	return len(p.instances)
+}
+
+// Healthy instances in configured order. When every instance is cooling down, try them all anyway
+func (p *InstancePool) candidates() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var (
+		now     = p.now()
+		healthy []int
+		all     []int
+	)
+
+	for i, instance := range p.instances {
+		all = append(all, i)
+		if until, ok := p.unhealthyUntil[instance.Host]; !ok || now.After(until) {
+			healthy = append(healthy, i)
+		}
+	}
+
+	if len(healthy) == 0 {
+		return all
+	}
+
+	return healthy
+}
+
+func (p *InstancePool) newAttempt(req *http.Request, instance *url.URL) (*http.Request, context.CancelFunc, error) {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	if p.attemptTimeout > 0 {
+		ctx, cancel = context.WithTimeout(req.Context(), p.attemptTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(req.Context())
+	}
+
+	attempt := req.Clone(ctx)
+	attempt.URL.Scheme = instance.Scheme
+	attempt.URL.Host = instance.Host
+	attempt.Host = ""
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, nil, err
+		}
+
+		attempt.Body = body
+	}
+
+	return attempt, cancel, nil
+}
+
+func (p *InstancePool) markHealthy(index int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.active = index
+	delete(p.unhealthyUntil, p.instances[index].Host)
+}
+
+func (p *InstancePool) markUnhealthy(index int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.unhealthyUntil[p.instances[index].Host] = p.now().Add(p.cooldown)
+}
+
+func isUnhealthyStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// Release the attempt's timeout once the response body has been read
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
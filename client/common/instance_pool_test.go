@@ -0,0 +1,88 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestServer(statusCode *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(*statusCode)
+	}))
+}
+
+func TestInstancePoolFailover(t *testing.T) {
+	primaryStatus, secondaryStatus := http.StatusServiceUnavailable, http.StatusOK
+	primary, secondary := newTestServer(&primaryStatus), newTestServer(&secondaryStatus)
+	defer primary.Close()
+	defer secondary.Close()
+
+	now := time.Now()
+	pool, err := NewInstancePool([]string{primary.URL, secondary.URL}, time.Minute, time.Second, nil)
+	if err != nil {
+		t.Fatalf("could not create instance pool: %v", err)
+	}
+	pool.now = func() time.Time { return now }
+
+	client := &http.Client{Transport: pool}
+	get := func() int {
+		res, err := client.Get(primary.URL + "/r/dogs")
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	// Primary fails, the request is retried against the secondary instance
+	assertVal("StatusCode", http.StatusOK, get(), t)
+	assertVal("Active", hostOf(secondary), pool.Active(), t)
+
+	// Primary is cooling down so it is skipped even after it recovers
+	primaryStatus = http.StatusOK
+	secondaryStatus = http.StatusTooManyRequests
+	assertVal("StatusCode", http.StatusTooManyRequests, get(), t)
+
+	// Every instance is unhealthy, all of them are tried in order
+	secondaryStatus = http.StatusOK
+	assertVal("StatusCode", http.StatusOK, get(), t)
+	assertVal("Active", hostOf(primary), pool.Active(), t)
+
+	// Cooldown expires, secondary is healthy again but the primary is preferred
+	now = now.Add(2 * time.Minute)
+	assertVal("StatusCode", http.StatusOK, get(), t)
+	assertVal("Active", hostOf(primary), pool.Active(), t)
+}
+
+func TestInstancePoolIgnoresOtherHosts(t *testing.T) {
+	status := http.StatusInternalServerError
+	primary, other := newTestServer(&status), newTestServer(&status)
+	defer primary.Close()
+	defer other.Close()
+
+	pool, err := NewInstancePool([]string{primary.URL}, time.Minute, time.Second, nil)
+	if err != nil {
+		t.Fatalf("could not create instance pool: %v", err)
+	}
+
+	res, err := (&http.Client{Transport: pool}).Get(other.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	res.Body.Close()
+
+	assertVal("StatusCode", http.StatusInternalServerError, res.StatusCode, t)
+	assertVal("Unhealthy", 0, len(pool.unhealthyUntil), t)
+}
+
+func hostOf(server *httptest.Server) string {
+	return server.Listener.Addr().String()
+}
+
+func assertVal[K comparable](context string, expected, got K, t *testing.T) {
+	if expected != got {
+		t.Errorf("assertion failed %s: for expected %v but got %v", context, expected, got)
+	}
+}
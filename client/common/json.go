@@ -0,0 +1,148 @@
+package common
+
+import (
+	"encoding/json"
+	"net/url"
+	"reddittui/model"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	LinkKind    = "t3"
+	CommentKind = "t1"
+	MoreKind    = "more"
+	ListingKind = "Listing"
+)
+
+// Generic wrapper around every object returned by the reddit json api
+type Thing struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+type Listing struct {
+	Kind string      `json:"kind"`
+	Data ListingData `json:"data"`
+}
+
+type ListingData struct {
+	After    string  `json:"after"`
+	Before   string  `json:"before"`
+	Children []Thing `json:"children"`
+}
+
+type LinkData struct {
+	Id                    string  `json:"id"`
+	Name                  string  `json:"name"`
+	Title                 string  `json:"title"`
+	Author                string  `json:"author"`
+	Subreddit             string  `json:"subreddit"`
+	SubredditNamePrefixed string  `json:"subreddit_name_prefixed"`
+	Permalink             string  `json:"permalink"`
+	Url                   string  `json:"url"`
+	IsSelf                bool    `json:"is_self"`
+	SelftextHtml          string  `json:"selftext_html"`
+	Score                 int     `json:"score"`
+	NumComments           int     `json:"num_comments"`
+	CreatedUtc            float64 `json:"created_utc"`
+	Promoted              bool    `json:"promoted"`
+	Over18                bool    `json:"over_18"`
+	Spoiler               bool    `json:"spoiler"`
+	Likes                 *bool   `json:"likes"`
+	Saved                 bool    `json:"saved"`
+}
+
+type CommentData struct {
+	Id                    string          `json:"id"`
+	Name                  string          `json:"name"`
+	ParentId              string          `json:"parent_id"`
+	Author                string          `json:"author"`
+	Body                  string          `json:"body"`
+	BodyHtml              string          `json:"body_html"`
+	Score                 int             `json:"score"`
+	ScoreHidden           bool            `json:"score_hidden"`
+	CreatedUtc            float64         `json:"created_utc"`
+	Depth                 int             `json:"depth"`
+	Replies               json.RawMessage `json:"replies"`
+	Permalink             string          `json:"permalink"`
+	LinkTitle             string          `json:"link_title"`
+	SubredditNamePrefixed string          `json:"subreddit_name_prefixed"`
+	Likes                 *bool           `json:"likes"`
+	Saved                 bool            `json:"saved"`
+}
+
+// Placeholder for replies missing from a comments response
+type MoreData struct {
+	Id       string   `json:"id"`
+	ParentId string   `json:"parent_id"`
+	Count    int      `json:"count"`
+	Depth    int      `json:"depth"`
+	Children []string `json:"children"`
+}
+
+// Response returned by the morechildren api
+type MoreChildrenResponse struct {
+	Json struct {
+		Errors [][]string `json:"errors"`
+		Data   struct {
+			Things []Thing `json:"things"`
+		} `json:"data"`
+	} `json:"json"`
+}
+
+// The api returns the logged in user's vote as likes, true for upvotes, false for downvotes and null otherwise
+func VoteFromLikes(likes *bool) int {
+	if likes == nil {
+		return model.NoVote
+	} else if *likes {
+		return model.Upvote
+	}
+
+	return model.Downvote
+}
+
+// Replies are returned as an empty string when a comment has no children, otherwise they are a listing
+func (c CommentData) GetReplies() (Listing, bool) {
+	var replies Listing
+	if len(c.Replies) == 0 || c.Replies[0] != '{' {
+		return replies, false
+	}
+
+	if err := json.Unmarshal(c.Replies, &replies); err != nil {
+		return replies, false
+	}
+
+	return replies, true
+}
+
+func FromUnixTimestamp(timestamp float64) time.Time {
+	return time.Unix(int64(timestamp), 0)
+}
+
+// Parse html embedded in json responses. Reddit escapes html entities in fields such as body_html
+func ParseEscapedHtml(escaped string) (HtmlNode, error) {
+	doc, err := html.Parse(strings.NewReader(html.UnescapeString(escaped)))
+	if err != nil {
+		return HtmlNode{}, err
+	}
+
+	return HtmlNode{Node: doc}, nil
+}
+
+// Convert a reddit url into the equivalent json api url, i.e. /r/dogs becomes /r/dogs.json
+func JsonUrl(rawUrl string) string {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return rawUrl
+	}
+
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/") + ".json"
+	if parsed.Path == ".json" {
+		parsed.Path = "/.json"
+	}
+
+	return parsed.String()
+}
@@ -0,0 +1,55 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+)
+
+var ErrOffline = errors.New("not available offline")
+
+// Tracks whether posts and comments should be served from the cache without going to the network.
+// Offline mode is either forced with the --offline flag, or detected when the network is unreachable.
+// Detected offline mode ends as soon as a request reaches the server again
+type OfflineMode struct {
+	forced   bool
+	detected *atomic.Bool
+}
+
+func NewOfflineMode(forced bool) *OfflineMode {
+	return &OfflineMode{
+		forced:   forced,
+		detected: &atomic.Bool{},
+	}
+}
+
+// Nil offline modes are always online so clients built without one keep working
+func (o *OfflineMode) IsOffline() bool {
+	return o != nil && (o.forced || o.detected.Load())
+}
+
+// Requests are never sent to the server when offline mode is forced
+func (o *OfflineMode) IsForced() bool {
+	return o != nil && o.forced
+}
+
+// Record the result of a request sent to the server, switching to offline mode when it could not connect
+func (o *OfflineMode) Update(err error) {
+	if o == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	o.detected.Store(IsNetworkError(err))
+}
+
+// Errors caused by the server being unreachable rather than by the server rejecting the request. Slow
+// servers are not a sign of being offline, so timeouts are not included
+func IsNetworkError(err error) bool {
+	var (
+		opErr  *net.OpError
+		dnsErr *net.DNSError
+	)
+
+	return errors.As(err, &opErr) || errors.As(err, &dnsErr)
+}
@@ -0,0 +1,52 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestOfflineModeDetection(t *testing.T) {
+	offline := NewOfflineMode(false)
+	assertVal("initially offline", false, offline.IsOffline(), t)
+
+	// Unreachable servers switch to offline mode
+	_, err := http.Get("http://127.0.0.1:1")
+	offline.Update(err)
+	assertVal("offline after connection error", true, offline.IsOffline(), t)
+	assertVal("forced", false, offline.IsForced(), t)
+
+	// Cancelled requests say nothing about the network
+	offline.Update(context.Canceled)
+	assertVal("offline after cancel", true, offline.IsOffline(), t)
+
+	// Reaching the server again, even if it returns an error, ends offline mode
+	offline.Update(&ResponseError{Err: ErrNotFound})
+	assertVal("offline after response", false, offline.IsOffline(), t)
+
+	forced := NewOfflineMode(true)
+	forced.Update(nil)
+	assertVal("forced offline", true, forced.IsOffline(), t)
+
+	var none *OfflineMode
+	assertVal("nil offline mode", false, none.IsOffline(), t)
+}
+
+func TestWrapRequestError(t *testing.T) {
+	type testCase struct {
+		err      error
+		expected error
+	}
+
+	tests := []testCase{
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, ErrOffline},
+		{&net.DNSError{Err: "no such host", Name: "old.reddit.com"}, ErrOffline},
+		{context.DeadlineExceeded, ErrTimeout},
+	}
+
+	for _, test := range tests {
+		assertVal(test.err.Error(), true, errors.Is(WrapRequestError(test.err), test.expected), t)
+	}
+}
@@ -0,0 +1,64 @@
+package common
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+const Over18FileName = "over18"
+
+// Cookies that skip the over 18 confirmation page on old reddit and the nsfw landing page on redlib
+var over18Cookies = []*http.Cookie{
+	{Name: "over18", Value: "1"},
+	{Name: "show_nsfw", Value: "on"},
+}
+
+// Whether the user confirmed they are over 18. The confirmation is saved to a file so it is only asked for once
+type Over18Consent struct {
+	path      string
+	confirmed *atomic.Bool
+}
+
+func NewOver18Consent(path string) *Over18Consent {
+	consent := &Over18Consent{
+		path:      path,
+		confirmed: &atomic.Bool{},
+	}
+
+	if len(path) > 0 {
+		_, err := os.Stat(path)
+		consent.confirmed.Store(err == nil)
+	}
+
+	return consent
+}
+
+// Nil consents are never confirmed
+func (c *Over18Consent) IsConfirmed() bool {
+	return c != nil && c.confirmed.Load()
+}
+
+// Confirm for this and every later run. The confirmation still applies to this run if it cannot be saved
+func (c *Over18Consent) Confirm() error {
+	if c == nil {
+		return nil
+	}
+
+	c.confirmed.Store(true)
+	if len(c.path) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0750); err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, nil, 0644)
+}
+
+// Old reddit redirects over 18 subreddits and posts to a confirmation page instead of returning an error
+func IsOver18Redirect(res *http.Response) bool {
+	return res.Request != nil && res.Request.URL.Path == "/over18"
+}
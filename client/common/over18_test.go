@@ -0,0 +1,45 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestOver18Consent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/over18" {
+			return
+		}
+
+		if _, err := r.Cookie("over18"); err != nil {
+			http.Redirect(w, r, "/over18?dest="+r.URL.Path, http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), Over18FileName)
+	consent := NewOver18Consent(path)
+	client := &http.Client{Transport: HeaderTransport{Transport: http.DefaultTransport, Over18: consent}}
+
+	res, err := client.Get(server.URL + "/r/nsfw")
+	if err != nil {
+		t.Fatalf("unexpected error before confirming: %v", err)
+	}
+	assertVal("redirected before confirming", true, IsOver18Redirect(res), t)
+
+	if err := consent.Confirm(); err != nil {
+		t.Fatalf("unexpected error confirming: %v", err)
+	}
+
+	res, err = client.Get(server.URL + "/r/nsfw")
+	if err != nil {
+		t.Fatalf("unexpected error after confirming: %v", err)
+	}
+	assertVal("redirected after confirming", false, IsOver18Redirect(res), t)
+
+	// The confirmation is remembered on the next run
+	assertVal("confirmed on next run", true, NewOver18Consent(path).IsConfirmed(), t)
+	assertVal("confirmed without a file", false, NewOver18Consent("").IsConfirmed(), t)
+}
@@ -0,0 +1,119 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Proxy value that sends requests to a server directly, used to exempt a server from the default proxy
+const DirectProxy = "direct"
+
+// Chooses the proxy for each request. A proxy configured for the request's server wins, then hosts in the
+// no proxy list are sent directly, then the default proxy is used. Without a default proxy the standard
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply
+type ProxySelector struct {
+	defaultProxy *url.URL
+	overrides    map[string]*url.URL
+	noProxy      []string
+	environment  func(*http.Request) (*url.URL, error)
+}
+
+func NewProxySelector(defaultProxy string, overrides map[string]string, noProxy []string) (*ProxySelector, error) {
+	selector := &ProxySelector{
+		overrides:   make(map[string]*url.URL),
+		environment: http.ProxyFromEnvironment,
+	}
+
+	var err error
+	if selector.defaultProxy, err = ParseProxyUrl(defaultProxy); err != nil {
+		return nil, err
+	}
+
+	for server, proxy := range overrides {
+		if selector.overrides[proxyHost(server)], err = ParseProxyUrl(proxy); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, host := range noProxy {
+		if host = strings.ToLower(strings.TrimSpace(host)); len(host) > 0 {
+			selector.noProxy = append(selector.noProxy, host)
+		}
+	}
+
+	return selector, nil
+}
+
+// Parse a proxy url, only http, https and socks5 proxies are supported. Returns nil for direct connections
+func ParseProxyUrl(proxy string) (*url.URL, error) {
+	proxy = strings.TrimSpace(proxy)
+	if len(proxy) == 0 || strings.EqualFold(proxy, DirectProxy) {
+		return nil, nil
+	}
+
+	parsed, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %s: %w", proxy, err)
+	}
+
+	switch parsed.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy %s: scheme must be http, https, socks5 or socks5h", proxy)
+	}
+
+	if len(parsed.Host) == 0 {
+		return nil, fmt.Errorf("invalid proxy %s: missing host", proxy)
+	}
+
+	return parsed, nil
+}
+
+// Proxy function for http.Transport
+func (p *ProxySelector) Proxy(req *http.Request) (*url.URL, error) {
+	host := strings.ToLower(req.URL.Hostname())
+	if proxy, ok := p.overrides[host]; ok {
+		return proxy, nil
+	}
+
+	for _, noProxy := range p.noProxy {
+		if matchesNoProxy(host, noProxy) {
+			return nil, nil
+		}
+	}
+
+	if p.defaultProxy != nil {
+		return p.defaultProxy, nil
+	}
+
+	return p.environment(req)
+}
+
+// Entries match the host and its subdomains. Entries starting with a dot only match subdomains and
+// * matches every host
+func matchesNoProxy(host, noProxy string) bool {
+	if noProxy == "*" {
+		return true
+	} else if strings.HasPrefix(noProxy, ".") {
+		return strings.HasSuffix(host, noProxy)
+	}
+
+	return host == noProxy || strings.HasSuffix(host, "."+noProxy)
+}
+
+// Host of a configured server, which may be written as a domain or as a url
+func proxyHost(server string) string {
+	server = strings.ToLower(strings.TrimSpace(server))
+	if _, rest, ok := strings.Cut(server, "://"); ok {
+		server = rest
+	}
+
+	server, _, _ = strings.Cut(server, "/")
+	if host, _, ok := strings.Cut(server, ":"); ok {
+		return host
+	}
+
+	return server
+}
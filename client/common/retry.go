@@ -0,0 +1,101 @@
+package common
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Longest wait before retrying. Servers asking to wait longer fail immediately instead of
+// leaving the loading modal up
+const maxRetryDelay = 30 * time.Second
+
+// Round tripper that retries rate limited reads, server errors and timeouts with exponential backoff
+type RetryTransport struct {
+	Transport  http.RoundTripper
+	MaxRetries int
+	Delay      time.Duration
+	sleep      func(context.Context, time.Duration) error
+}
+
+func NewRetryTransport(transport http.RoundTripper, maxRetries int, delay time.Duration) RetryTransport {
+	return RetryTransport{
+		Transport:  transport,
+		MaxRetries: maxRetries,
+		Delay:      delay,
+		sleep:      sleepContext,
+	}
+}
+
+func (t RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	maxRetries := t.MaxRetries
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		// Writes such as replies may have gone through even when the response failed, so they only
+		// get one attempt rather than being posted twice
+		maxRetries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		retry, err := t.cloneRequest(req)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := t.Transport.RoundTrip(retry)
+		if attempt >= maxRetries || req.Context().Err() != nil {
+			return res, err
+		}
+
+		delay := t.Delay << attempt
+		if err != nil {
+			if !IsTransient(WrapRequestError(err)) {
+				return res, err
+			}
+		} else if res.StatusCode == http.StatusTooManyRequests {
+			delay = max(delay, ParseRetryAfter(res.Header.Get("Retry-After"), time.Now()))
+		} else if res.StatusCode < http.StatusInternalServerError {
+			return res, err
+		}
+
+		if delay > maxRetryDelay {
+			return res, err
+		}
+
+		if res != nil {
+			res.Body.Close()
+		}
+
+		slog.Warn("Retrying request", "url", req.URL.String(), "attempt", attempt+1, "delay", delay)
+		if err := t.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t RetryTransport) cloneRequest(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	retry.Body = body
+	return retry, nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
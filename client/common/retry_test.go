@@ -0,0 +1,89 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	type testCase struct {
+		statuses         []int
+		maxRetries       int
+		expectedStatus   int
+		expectedRequests int
+		expectedDelays   []time.Duration
+	}
+
+	tests := []testCase{
+		{[]int{http.StatusOK}, 2, http.StatusOK, 1, nil},
+		{[]int{http.StatusBadGateway, http.StatusOK}, 2, http.StatusOK, 2, []time.Duration{time.Second}},
+		{[]int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}, 2, http.StatusBadGateway, 3, []time.Duration{time.Second, 2 * time.Second}},
+		{[]int{http.StatusTooManyRequests, http.StatusOK}, 2, http.StatusOK, 2, []time.Duration{5 * time.Second}},
+		{[]int{http.StatusNotFound}, 2, http.StatusNotFound, 1, nil},
+		{[]int{http.StatusBadGateway}, 0, http.StatusBadGateway, 1, nil},
+	}
+
+	for _, test := range tests {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			status := test.statuses[min(requests, len(test.statuses)-1)]
+			requests++
+
+			if status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "5")
+			}
+			w.WriteHeader(status)
+		}))
+
+		var delays []time.Duration
+		transport := NewRetryTransport(http.DefaultTransport, test.maxRetries, time.Second)
+		transport.sleep = func(ctx context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		}
+
+		client := &http.Client{Transport: transport}
+		res, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		res.Body.Close()
+		server.Close()
+
+		assertVal("StatusCode", test.expectedStatus, res.StatusCode, t)
+		assertVal("requests", test.expectedRequests, requests, t)
+		assertVal("retries", len(test.expectedDelays), len(delays), t)
+		for i := range min(len(delays), len(test.expectedDelays)) {
+			assertVal("delay", test.expectedDelays[i], delays[i], t)
+		}
+	}
+}
+
+func TestRetryTransportWrites(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	transport := NewRetryTransport(http.DefaultTransport, 2, time.Second)
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		t.Errorf("unexpected retry after %v", d)
+		return nil
+	}
+
+	client := &http.Client{Transport: transport}
+	res, err := client.Post(server.URL, "application/x-www-form-urlencoded", strings.NewReader("text=reply"))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	res.Body.Close()
+
+	assertVal("StatusCode", http.StatusServiceUnavailable, res.StatusCode, t)
+	assertVal("requests", 1, requests, t)
+}
@@ -1,9 +1,12 @@
 package posts
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"reddittui/client/cache"
 	"reddittui/client/common"
 	"reddittui/config"
@@ -11,18 +14,25 @@ import (
 	"reddittui/utils"
 	"strings"
 	"time"
+)
 
-	"golang.org/x/net/html"
+const (
+	nsfwTag    = "NSFW"
+	spoilerTag = "spoiler"
 )
 
 type RedditPostsClient struct {
 	BaseUrl          string
+	ServerType       string
 	CacheTtl         time.Duration
 	Client           *http.Client
 	Cache            cache.PostsCache
 	Parser           PostsParser
 	KeywordFilters   []string
 	SubredditFilters []string
+	NsfwPolicy       string
+	SpoilerPolicy    string
+	Offline          *common.OfflineMode
 }
 
 func NewRedditPostsClient(
@@ -31,63 +41,186 @@ func NewRedditPostsClient(
 	postsCache cache.PostsCache,
 	configuration config.Config,
 ) RedditPostsClient {
-	var parser PostsParser
+	var (
+		parser     PostsParser
+		serverType = strings.ToLower(configuration.Server.Type)
+	)
 
-	switch strings.ToLower(configuration.Server.Type) {
+	switch serverType {
 	case "old":
-		parser = OldRedditPostsParser{}
+		parser = OldRedditPostsParser{baseUrl}
 	case "redlib":
 		parser = RedlibParser{baseUrl}
+	case "json":
+		parser = JsonPostsParser{baseUrl}
 	default:
 		panic("Unrecognized server type in configuration: " + configuration.Server.Type)
 	}
 
 	return RedditPostsClient{
 		BaseUrl:          baseUrl,
+		ServerType:       serverType,
 		CacheTtl:         time.Duration(configuration.Client.CacheTtlSeconds) * time.Second,
 		Client:           httpClient,
 		Cache:            postsCache,
 		Parser:           parser,
 		KeywordFilters:   configuration.Filter.Keywords,
 		SubredditFilters: configuration.Filter.Subreddits,
+		NsfwPolicy:       model.NormalizePostPolicy(configuration.Filter.Nsfw),
+		SpoilerPolicy:    model.NormalizePostPolicy(configuration.Filter.Spoilers),
 	}
 }
 
-func (r RedditPostsClient) GetHomePosts(after string) (model.Posts, error) {
+// Client for the logged in user's front page, served as json by the api. Comments urls still point to the
+// configured server so the posts open the same way as logged out ones
+func NewAccountPostsClient(
+	apiUrl,
+	baseUrl string,
+	httpClient *http.Client,
+	postsCache cache.PostsCache,
+	configuration config.Config,
+) RedditPostsClient {
+	client := NewRedditPostsClient(apiUrl, httpClient, postsCache, configuration)
+	client.ServerType = "api"
+	client.Parser = JsonPostsParser{baseUrl}
+	return client
+}
+
+func (r RedditPostsClient) GetHomePosts(ctx context.Context, sort model.PostSort, after string) (model.Posts, error) {
 	timer := utils.NewTimer("total time to retrieve home posts")
 	defer timer.StopAndLog()
 
-	postsUrl := r.BuildPostsUrl("", after)
-	posts, err := r.tryGetCachedPosts(postsUrl)
+	postsUrl := r.BuildPostsUrl("", sort, after)
+	posts, err := r.tryGetCachedPosts(ctx, postsUrl, len(after) == 0)
 	posts.IsHome = true
+	posts.Sort = sort
 
 	return posts, err
 }
 
-func (r RedditPostsClient) GetSubredditPosts(subreddit string, after string) (model.Posts, error) {
+func (r RedditPostsClient) GetSubredditPosts(ctx context.Context, subreddit string, sort model.PostSort, after string) (model.Posts, error) {
 	timer := utils.NewTimer("total time to retrieve subreddit posts")
 	defer timer.StopAndLog()
 
-	postsUrl := r.BuildPostsUrl(subreddit, after)
-	posts, err := r.tryGetCachedPosts(postsUrl)
+	postsUrl := r.BuildPostsUrl(subreddit, sort, after)
+	posts, err := r.tryGetCachedPosts(ctx, postsUrl, len(after) == 0)
+	posts.Subreddit = subreddit
+	posts.Sort = sort
+
+	return posts, err
+}
+
+// Search posts across reddit, or only within subreddit when it is not empty
+func (r RedditPostsClient) SearchPosts(ctx context.Context, query, subreddit string, sort model.PostSort, after string) (model.Posts, error) {
+	timer := utils.NewTimer("total time to retrieve search results")
+	defer timer.StopAndLog()
+
+	searchUrl := r.BuildSearchUrl(query, subreddit, sort, after)
+	posts, err := r.tryGetCachedPosts(ctx, searchUrl, len(after) == 0)
+	posts.Query = query
+	posts.Subreddit = subreddit
+	posts.Sort = sort
+
+	return posts, err
+}
+
+// Get the posts and comments submitted by a user
+func (r RedditPostsClient) GetUserPosts(ctx context.Context, user string, sort model.PostSort, after string) (model.Posts, error) {
+	timer := utils.NewTimer("total time to retrieve user posts")
+	defer timer.StopAndLog()
+
+	userUrl := r.BuildUserUrl(user, sort, after)
+	posts, err := r.tryGetCachedPosts(ctx, userUrl, len(after) == 0)
+	posts.User = user
+	posts.Sort = sort
+	fillAuthor(&posts, user)
+
+	return posts, err
+}
+
+// Fetch a page of home or subreddit posts from the server, replacing the cached copy even if it has not expired
+func (r RedditPostsClient) RefreshPosts(ctx context.Context, subreddit string, sort model.PostSort, after string) (model.Posts, error) {
+	timer := utils.NewTimer("total time to refresh posts")
+	defer timer.StopAndLog()
+
+	postsUrl := r.BuildPostsUrl(subreddit, sort, after)
+	posts, err := r.fetchPosts(ctx, postsUrl)
+	posts.IsHome = len(subreddit) == 0
 	posts.Subreddit = subreddit
+	posts.Sort = sort
 
 	return posts, err
 }
 
-// Try to get posts from cache. If they are not present, fetch them and cache the results
-func (r RedditPostsClient) tryGetCachedPosts(postsUrl string) (posts model.Posts, err error) {
+// Fetch a fresh copy of the first page of stale posts from the server, skipping the cache
+func (r RedditPostsClient) RevalidatePosts(ctx context.Context, stale model.Posts) (model.Posts, error) {
+	timer := utils.NewTimer("total time to revalidate posts")
+	defer timer.StopAndLog()
+
+	var postsUrl string
+	switch stale.Kind() {
+	case model.HomePosts:
+		postsUrl = r.BuildPostsUrl("", stale.Sort, "")
+	case model.SearchPosts:
+		postsUrl = r.BuildSearchUrl(stale.Query, stale.Subreddit, stale.Sort, "")
+	case model.UserPosts:
+		postsUrl = r.BuildUserUrl(stale.User, stale.Sort, "")
+	default:
+		postsUrl = r.BuildPostsUrl(stale.Subreddit, stale.Sort, "")
+	}
+
+	posts, err := r.fetchPosts(ctx, postsUrl)
+	posts.IsHome = stale.IsHome
+	posts.Subreddit = stale.Subreddit
+	posts.Query = stale.Query
+	posts.User = stale.User
+	posts.Sort = stale.Sort
+	fillAuthor(&posts, stale.User)
+
+	return posts, err
+}
+
+// Some servers leave out the author on user pages since every entry was submitted by the user
+func fillAuthor(posts *model.Posts, user string) {
+	for i := range posts.Posts {
+		if len(posts.Posts[i].Author) == 0 {
+			posts.Posts[i].Author = user
+		}
+	}
+}
+
+// Try to get posts from cache. If they are not present, fetch them and cache the results. When
+// allowStale is set, expired posts are returned marked as stale for the caller to revalidate
+func (r RedditPostsClient) tryGetCachedPosts(ctx context.Context, postsUrl string, allowStale bool) (posts model.Posts, err error) {
 	timer := utils.NewTimer("fetching posts from cache")
-	posts, err = r.Cache.Get(postsUrl)
+	posts, err = r.Cache.Get(ctx, postsUrl)
+	timer.StopAndLog()
+
+	expired := errors.Is(err, common.ErrCacheEntryExpired)
 	if err == nil {
 		// return cached data
-		timer.StopAndLog()
+		return r.filterPosts(posts), nil
+	} else if expired && r.Offline.IsOffline() {
+		// There is no server to refresh expired posts from while offline
+		return r.filterPosts(posts), nil
+	} else if expired && allowStale {
+		posts.Stale = true
+		return r.filterPosts(posts), nil
+	}
+
+	fetched, err := r.fetchPosts(ctx, postsUrl)
+	if expired && errors.Is(err, common.ErrOffline) {
+		slog.Warn("Server unreachable, using expired posts", "url", postsUrl)
 		return r.filterPosts(posts), nil
 	}
-	timer.StopAndLog()
 
-	timer = utils.NewTimer("getting posts from server")
-	posts, err = r.getPosts(postsUrl)
+	return fetched, err
+}
+
+// Fetch posts from the server and cache the results
+func (r RedditPostsClient) fetchPosts(ctx context.Context, postsUrl string) (posts model.Posts, err error) {
+	timer := utils.NewTimer("getting posts from server")
+	posts, err = r.getPosts(ctx, postsUrl)
 	if err != nil {
 		timer.StopAndLog()
 		return posts, err
@@ -99,53 +232,60 @@ func (r RedditPostsClient) tryGetCachedPosts(postsUrl string) (posts model.Posts
 	timer.StopAndLog()
 
 	timer = utils.NewTimer("putting posts in cache")
-	r.Cache.Put(posts, postsUrl)
+	r.Cache.Put(ctx, posts, postsUrl)
 	timer.StopAndLog()
 	return posts, nil
 }
 
-func (r RedditPostsClient) getPosts(url string) (posts model.Posts, err error) {
-	req, err := http.NewRequest("GET", url, nil)
+func (r RedditPostsClient) getPosts(ctx context.Context, url string) (posts model.Posts, err error) {
+	if r.Offline.IsForced() {
+		return posts, common.ErrOffline
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", r.requestUrl(url), nil)
 	if err != nil {
 		return posts, err
 	}
 
-	req.Header.Add(common.UserAgentHeaderKey, common.UserAgentHeaderValue)
-
 	timer := utils.NewTimer("fetching posts from server")
 	res, err := r.Client.Do(req)
 	timer.StopAndLog("url", url)
+	r.Offline.Update(err)
 
 	if err != nil {
-		return posts, err
-	} else if res.StatusCode != http.StatusOK {
-		// Treat all non-200s as 404s
-		return posts, common.ErrCannotLoadPosts
+		return posts, common.WrapRequestError(err)
 	}
 
 	defer res.Body.Close()
 
-	timer = utils.NewTimer("parsing posts html")
-	doc, err := html.Parse(res.Body)
-	timer.StopAndLog()
-	if err != nil {
-		return posts, err
+	if res.StatusCode != http.StatusOK {
+		return posts, common.NewResponseError(res)
+	} else if common.IsOver18Redirect(res) {
+		return posts, common.ErrOver18
 	}
 
-	timer = utils.NewTimer("converting posts html")
-	posts = r.Parser.ParsePosts(common.HtmlNode{Node: doc})
+	timer = utils.NewTimer("converting posts")
+	posts, err = r.Parser.ParsePosts(res.Body)
 	timer.StopAndLog()
-	if len(posts.Posts) == 0 {
+	if err != nil {
+		return posts, err
+	} else if len(posts.Posts) == 0 && !r.isUserUrl(url) {
 		// if there are no posts, assume 404.
 		// reddit redirect invalid subreddits requests to some search page instead of doing 404
+		// users that do not exist do get a 404, so an empty user page belongs to someone who has not posted yet
 		slog.Warn("Subreddit not found")
 		return posts, common.ErrNotFound
 	}
 
-	posts.Expiry = time.Now().Add(r.CacheTtl)
+	posts.Fetched = time.Now()
+	posts.Expiry = posts.Fetched.Add(r.CacheTtl)
 	return posts, nil
 }
 
+func (r RedditPostsClient) isUserUrl(url string) bool {
+	return strings.HasPrefix(url, r.BaseUrl+"/user/")
+}
+
 func (r RedditPostsClient) filterPosts(posts model.Posts) model.Posts {
 	var filteredPosts []model.Post
 
@@ -166,6 +306,28 @@ outer:
 			}
 		}
 
+		post.Tags = nil
+		post.Masked = false
+		if post.Nsfw {
+			if r.NsfwPolicy == model.HidePosts {
+				slog.Debug("filtering nsfw post", "title", post.PostTitle)
+				continue
+			} else if r.NsfwPolicy == model.TagPosts || r.NsfwPolicy == model.MaskPosts {
+				post.Tags = append(post.Tags, nsfwTag)
+				post.Masked = post.Masked || r.NsfwPolicy == model.MaskPosts
+			}
+		}
+
+		if post.Spoiler {
+			if r.SpoilerPolicy == model.HidePosts {
+				slog.Debug("filtering spoiler post", "title", post.PostTitle)
+				continue
+			} else if r.SpoilerPolicy == model.TagPosts || r.SpoilerPolicy == model.MaskPosts {
+				post.Tags = append(post.Tags, spoilerTag)
+				post.Masked = post.Masked || r.SpoilerPolicy == model.MaskPosts
+			}
+		}
+
 		filteredPosts = append(filteredPosts, post)
 	}
 
@@ -173,15 +335,87 @@ outer:
 	return posts
 }
 
-func (r RedditPostsClient) BuildPostsUrl(subreddit, after string) string {
-	afterParam := ""
+// Url to request from the server. The json api serves the same listings under a .json suffix
+func (r RedditPostsClient) requestUrl(url string) string {
+	if r.ServerType == "json" {
+		return common.JsonUrl(url)
+	}
+
+	return url
+}
+
+// Build the url for a page of posts. The url doubles as the cache key, so different sorts of the
+// same subreddit are cached separately
+func (r RedditPostsClient) BuildPostsUrl(subreddit string, sort model.PostSort, after string) string {
+	var path strings.Builder
+	if len(subreddit) > 0 {
+		fmt.Fprintf(&path, "/r/%s", subreddit)
+	}
+
+	// Hot is the default sort, leave it out so the url matches the one reddit uses
+	if !sort.IsDefault() {
+		fmt.Fprintf(&path, "/%s", sort.Sort)
+	}
+
+	query := url.Values{}
+	if sort.HasTimeframe() && len(sort.Timeframe) > 0 {
+		query.Set("t", sort.Timeframe)
+	}
 	if len(after) > 0 {
-		afterParam = fmt.Sprintf("?after=%s", after)
+		query.Set("after", after)
+	}
+
+	if len(query) == 0 {
+		return fmt.Sprintf("%s%s", r.BaseUrl, path.String())
 	}
 
+	return fmt.Sprintf("%s%s?%s", r.BaseUrl, path.String(), query.Encode())
+}
+
+// Build the url for a page of search results. Searches within a subreddit need restrict_sr,
+// otherwise reddit searches everywhere
+func (r RedditPostsClient) BuildSearchUrl(query, subreddit string, sort model.PostSort, after string) string {
+	path := "/search"
+	if len(subreddit) > 0 {
+		path = fmt.Sprintf("/r/%s/search", subreddit)
+	}
+
+	params := url.Values{}
+	params.Set("q", query)
 	if len(subreddit) > 0 {
-		return fmt.Sprintf("%s/r/%s%s", r.BaseUrl, subreddit, afterParam)
+		params.Set("restrict_sr", "on")
+	}
+	if len(sort.Sort) > 0 && sort.Sort != model.SortRelevance {
+		params.Set("sort", sort.Sort)
+	}
+	if len(sort.Timeframe) > 0 {
+		params.Set("t", sort.Timeframe)
+	}
+	if len(after) > 0 {
+		params.Set("after", after)
+	}
+
+	return fmt.Sprintf("%s%s?%s", r.BaseUrl, path, params.Encode())
+}
+
+// Build the url for a page of a user's posts and comments. User pages take the sort as a query
+// parameter rather than as part of the path
+func (r RedditPostsClient) BuildUserUrl(user string, sort model.PostSort, after string) string {
+	params := url.Values{}
+	if len(sort.Sort) > 0 && sort.Sort != model.SortNew {
+		params.Set("sort", sort.Sort)
+	}
+	if sort.HasTimeframe() && len(sort.Timeframe) > 0 {
+		params.Set("t", sort.Timeframe)
+	}
+	if len(after) > 0 {
+		params.Set("after", after)
+	}
+
+	userUrl := fmt.Sprintf("%s/user/%s", r.BaseUrl, user)
+	if len(params) == 0 {
+		return userUrl
 	}
 
-	return fmt.Sprintf("%s%s", r.BaseUrl, afterParam)
+	return fmt.Sprintf("%s?%s", userUrl, params.Encode())
 }
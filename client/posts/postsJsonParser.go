@@ -0,0 +1,118 @@
+package posts
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/url"
+	"reddittui/client/common"
+	"reddittui/model"
+	"reddittui/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type JsonPostsParser struct {
+	BaseUrl string
+}
+
+func (p JsonPostsParser) ParsePosts(body io.Reader) (model.Posts, error) {
+	var (
+		posts   model.Posts
+		listing common.Listing
+	)
+
+	if err := json.NewDecoder(body).Decode(&listing); err != nil {
+		return posts, err
+	}
+
+	now := time.Now()
+	for _, child := range listing.Data.Children {
+		if child.Kind == common.CommentKind {
+			// User pages list comments along with submitted posts
+			var comment common.CommentData
+			if err := json.Unmarshal(child.Data, &comment); err != nil {
+				slog.Debug("Error decoding comment", "error", err)
+				continue
+			}
+
+			posts.Posts = append(posts.Posts, p.parseUserComment(comment, now))
+			continue
+		} else if child.Kind != common.LinkKind {
+			continue
+		}
+
+		var link common.LinkData
+		if err := json.Unmarshal(child.Data, &link); err != nil {
+			slog.Debug("Error decoding post", "error", err)
+			continue
+		}
+
+		if link.Promoted {
+			// Skip ads and promotional content
+			continue
+		}
+
+		posts.Posts = append(posts.Posts, p.parsePost(link, now))
+	}
+
+	posts.After = listing.Data.After
+	return posts, nil
+}
+
+func (p JsonPostsParser) parsePost(link common.LinkData, now time.Time) model.Post {
+	created := common.FromUnixTimestamp(link.CreatedUtc)
+
+	post := model.Post{
+		Id:            link.Id,
+		PostTitle:     link.Title,
+		Author:        link.Author,
+		Subreddit:     link.SubredditNamePrefixed,
+		FriendlyDate:  utils.FormatRelativeTime(created, now),
+		PostUrl:       link.Url,
+		TotalComments: strconv.Itoa(link.NumComments),
+		TotalLikes:    utils.FormatScore(link.Score),
+		Score:         link.Score,
+		Created:       created,
+		Nsfw:          link.Over18,
+		Spoiler:       link.Spoiler,
+		Vote:          common.VoteFromLikes(link.Likes),
+		Saved:         link.Saved,
+	}
+
+	commentsUrl, err := url.JoinPath(p.BaseUrl, link.Permalink)
+	if err != nil {
+		slog.Debug("Error parsing comments url", "error", err)
+	}
+	post.CommentsUrl = commentsUrl
+
+	return post
+}
+
+func (p JsonPostsParser) parseUserComment(comment common.CommentData, now time.Time) model.Post {
+	created := common.FromUnixTimestamp(comment.CreatedUtc)
+
+	post := model.Post{
+		Id:           comment.Id,
+		PostTitle:    strings.Join(strings.Fields(comment.Body), " "),
+		Author:       comment.Author,
+		Subreddit:    comment.SubredditNamePrefixed,
+		FriendlyDate: utils.FormatRelativeTime(created, now),
+		TotalLikes:   utils.FormatScore(comment.Score),
+		Score:        comment.Score,
+		Created:      created,
+		IsComment:    true,
+		LinkTitle:    comment.LinkTitle,
+		Vote:         common.VoteFromLikes(comment.Likes),
+		Saved:        comment.Saved,
+	}
+
+	commentsUrl, err := url.JoinPath(p.BaseUrl, comment.Permalink)
+	if err != nil {
+		slog.Debug("Error parsing comment url", "error", err)
+	}
+	post.CommentsUrl = commentsUrl
+
+	return post
+}
@@ -1,20 +1,39 @@
 package posts
 
 import (
+	"io"
 	"log/slog"
 	"net/url"
 	"reddittui/client/common"
 	"reddittui/model"
+	"reddittui/utils"
+	"regexp"
 	"strings"
+
+	"golang.org/x/net/html"
 )
 
+// Id of the post in a permalink such as /r/golang/comments/1abc2d/title/
+var postIdRegex = regexp.MustCompile(`/comments/([a-z0-9]+)`)
+
 type PostsParser interface {
-	ParsePosts(common.HtmlNode) model.Posts
+	ParsePosts(io.Reader) (model.Posts, error)
 }
 
-type OldRedditPostsParser struct{}
+type OldRedditPostsParser struct {
+	BaseUrl string
+}
+
+func (p OldRedditPostsParser) ParsePosts(body io.Reader) (model.Posts, error) {
+	root, err := parseHtml(body)
+	if err != nil {
+		return model.Posts{}, err
+	}
+
+	return p.parsePosts(root), nil
+}
 
-func (p OldRedditPostsParser) ParsePosts(root common.HtmlNode) model.Posts {
+func (p OldRedditPostsParser) parsePosts(root common.HtmlNode) model.Posts {
 	var (
 		posts       []model.Post
 		description string
@@ -26,10 +45,26 @@ func (p OldRedditPostsParser) ParsePosts(root common.HtmlNode) model.Posts {
 			continue
 		}
 
+		// User pages list comments along with submitted posts
+		if d.ClassContains("comment") {
+			posts = append(posts, p.parseUserComment(d))
+			continue
+		}
+
 		post := p.parsePost(d)
 		posts = append(posts, post)
 	}
 
+	// Search pages list posts using different markup than subreddit pages
+	for d := range root.FindDescendants("div", "search-result-link") {
+		if d.ClassContains("promoted") {
+			continue
+		}
+
+		post := p.parseSearchResult(d)
+		posts = append(posts, post)
+	}
+
 	// Parse description
 	for d := range root.FindDescendants("meta") {
 		if d.GetAttr("name") == "description" {
@@ -59,7 +94,12 @@ func (p OldRedditPostsParser) ParsePosts(root common.HtmlNode) model.Posts {
 }
 
 func (p OldRedditPostsParser) parsePost(n common.HtmlNode) model.Post {
-	var post model.Post
+	post := model.Post{
+		Id:      strings.TrimPrefix(n.GetAttr("data-fullname"), common.LinkKind+"_"),
+		Nsfw:    n.GetAttr("data-nsfw") == "true",
+		Spoiler: n.GetAttr("data-spoiler") == "true",
+	}
+
 	for c := range n.Descendants() {
 		cNode := common.HtmlNode{Node: c}
 
@@ -83,22 +123,122 @@ func (p OldRedditPostsParser) parsePost(n common.HtmlNode) model.Post {
 	return post
 }
 
+func (p OldRedditPostsParser) parseUserComment(n common.HtmlNode) model.Post {
+	post := model.Post{
+		Id:        strings.TrimPrefix(n.GetAttr("data-fullname"), common.CommentKind+"_"),
+		IsComment: true,
+		Author:    n.GetAttr("data-author"),
+		Subreddit: n.GetAttr("data-subreddit-prefixed"),
+	}
+
+	if permalink := n.GetAttr("data-permalink"); len(permalink) > 0 {
+		if commentsUrl, err := url.JoinPath(p.BaseUrl, permalink); err == nil {
+			post.CommentsUrl = commentsUrl
+		}
+	}
+
+	for c := range n.Descendants() {
+		cNode := common.HtmlNode{Node: c}
+
+		if cNode.NodeEquals("a", "title") {
+			post.LinkTitle = cNode.Text()
+			post.PostUrl = cNode.GetAttr("href")
+		} else if cNode.NodeEquals("a", "bylink") && cNode.GetAttr("data-event-action") == "permalink" {
+			post.CommentsUrl = cNode.GetAttr("href")
+		} else if cNode.NodeEquals("span", "score", "unvoted") {
+			post.TotalLikes = cNode.GetAttr("title")
+		} else if cNode.NodeEquals("time", "live-timestamp") {
+			post.FriendlyDate = cNode.Text()
+		} else if cNode.NodeEquals("div", "md") && len(post.PostTitle) == 0 {
+			post.PostTitle = nodeText(cNode)
+		}
+	}
+
+	return post
+}
+
+func (p OldRedditPostsParser) parseSearchResult(n common.HtmlNode) model.Post {
+	post := model.Post{
+		Id: strings.TrimPrefix(n.GetAttr("data-fullname"), common.LinkKind+"_"),
+	}
+
+	for c := range n.Descendants() {
+		cNode := common.HtmlNode{Node: c}
+
+		if cNode.NodeEquals("a", "search-title") {
+			post.PostTitle = cNode.Text()
+			post.PostUrl = cNode.GetAttr("href")
+			post.CommentsUrl = cNode.GetAttr("href")
+		} else if cNode.NodeEquals("a", "search-link") {
+			post.PostUrl = cNode.GetAttr("href")
+		} else if cNode.NodeEquals("a", "author") {
+			post.Author = cNode.Text()
+		} else if cNode.NodeEquals("a", "search-subreddit-link") {
+			post.Subreddit = cNode.Text()
+		} else if cNode.TagEquals("time") {
+			post.FriendlyDate = cNode.Text()
+		} else if cNode.NodeEquals("a", "search-comments") {
+			post.CommentsUrl = cNode.GetAttr("href")
+			if fields := strings.Fields(cNode.Text()); len(fields) > 0 {
+				post.TotalComments = fields[0]
+			}
+		} else if cNode.NodeEquals("span", "search-score") {
+			if fields := strings.Fields(cNode.Text()); len(fields) > 0 {
+				post.TotalLikes = fields[0]
+			}
+		} else if cNode.NodeEquals("span", "nsfw-stamp") {
+			post.Nsfw = true
+		} else if cNode.NodeEquals("span", "spoiler-stamp") {
+			post.Spoiler = true
+		}
+	}
+
+	return post
+}
+
 type RedlibParser struct {
 	BaseUrl string
 }
 
-func (p RedlibParser) ParsePosts(root common.HtmlNode) model.Posts {
+func (p RedlibParser) ParsePosts(body io.Reader) (model.Posts, error) {
+	root, err := parseHtml(body)
+	if err != nil {
+		return model.Posts{}, err
+	}
+
+	return p.parsePosts(root), nil
+}
+
+func (p RedlibParser) parsePosts(root common.HtmlNode) model.Posts {
 	var posts model.Posts
 
-	for d := range root.FindDescendants("div", "post") {
-		post := p.parsePost(d)
-		posts.Posts = append(posts.Posts, post)
+	for d := range root.FindDescendants("div") {
+		if d.ClassContains("post") {
+			post := p.parsePost(d)
+			posts.Posts = append(posts.Posts, post)
+		} else if d.ClassContains("comment") {
+			// User pages list comments along with submitted posts
+			posts.Posts = append(posts.Posts, p.parseUserComment(d))
+		}
 	}
 
 	if descriptionNode, ok := root.FindDescendantById("p", "sub_description"); ok {
 		posts.Description = descriptionNode.Text()
 	}
 
+	// Parse the next page link in the footer
+	for footer := range root.FindDescendants("footer") {
+		for a := range footer.FindDescendants("a") {
+			if a.GetAttr("accesskey") != "N" {
+				continue
+			}
+
+			if parsed, err := url.Parse(a.GetAttr("href")); err == nil {
+				posts.After = parsed.Query().Get("after")
+			}
+		}
+	}
+
 	return posts
 }
 
@@ -134,6 +274,40 @@ func (p RedlibParser) parsePost(n common.HtmlNode) model.Post {
 			post.TotalComments = cNode.GetAttr("title")
 		} else if cNode.NodeEquals("div", "post_score") {
 			post.TotalLikes = strings.TrimSpace(cNode.Text())
+		} else if cNode.NodeEquals("small", "nsfw") {
+			post.Nsfw = true
+		} else if cNode.NodeEquals("small", "spoiler") {
+			post.Spoiler = true
+		}
+	}
+
+	// Redlib listings have no fullnames, so the id is taken from the permalink
+	post.Id = postId(post.CommentsUrl)
+	return post
+}
+
+func (p RedlibParser) parseUserComment(n common.HtmlNode) model.Post {
+	post := model.Post{IsComment: true}
+	for c := range n.Descendants() {
+		cNode := common.HtmlNode{Node: c}
+
+		if cNode.NodeEquals("p", "comment_score") {
+			post.TotalLikes = strings.TrimSpace(cNode.Text())
+		} else if cNode.NodeEquals("a", "comment_link") {
+			commentsUrl, err := p.buildUrl(cNode.GetAttr("href"))
+			if err != nil {
+				slog.Debug("Error parsing comment url", "error", err)
+				continue
+			}
+
+			post.CommentsUrl = commentsUrl
+			post.Subreddit = strings.TrimPrefix(cNode.Text(), "Comment on ")
+		} else if cNode.NodeEquals("span", "created") {
+			post.FriendlyDate = cNode.Text()
+		} else if cNode.NodeEquals("p", "comment_title") {
+			post.LinkTitle = nodeText(cNode)
+		} else if cNode.NodeEquals("div", "comment_body") {
+			post.PostTitle = nodeText(cNode)
 		}
 	}
 
@@ -143,3 +317,36 @@ func (p RedlibParser) parsePost(n common.HtmlNode) model.Post {
 func (p RedlibParser) buildUrl(part string) (string, error) {
 	return url.JoinPath(p.BaseUrl, part)
 }
+
+func postId(permalink string) string {
+	if matches := postIdRegex.FindStringSubmatch(permalink); len(matches) == 2 {
+		return matches[1]
+	}
+
+	return ""
+}
+
+// Collapse the text of a node and its descendants into a single line
+func nodeText(n common.HtmlNode) string {
+	var sb strings.Builder
+	for c := range n.Descendants() {
+		if c.Type == html.TextNode {
+			sb.WriteString(c.Data)
+			sb.WriteString(" ")
+		}
+	}
+
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+func parseHtml(body io.Reader) (common.HtmlNode, error) {
+	timer := utils.NewTimer("parsing posts html")
+	defer timer.StopAndLog()
+
+	doc, err := html.Parse(body)
+	if err != nil {
+		return common.HtmlNode{}, err
+	}
+
+	return common.HtmlNode{Node: doc}, nil
+}
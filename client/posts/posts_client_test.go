@@ -0,0 +1,265 @@
+package posts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reddittui/client/cache"
+	"reddittui/client/common"
+	"reddittui/model"
+	"testing"
+	"time"
+)
+
+const testBaseUrl = "https://old.reddit.com"
+
+func TestBuildPostsUrl(t *testing.T) {
+	client := RedditPostsClient{BaseUrl: testBaseUrl}
+
+	tests := []struct {
+		subreddit string
+		sort      model.PostSort
+		after     string
+		want      string
+	}{
+		{"", model.PostSort{}, "", "https://old.reddit.com"},
+		{"", model.DefaultPostSort(), "t3_abc", "https://old.reddit.com?after=t3_abc"},
+		{"dogs", model.DefaultPostSort(), "", "https://old.reddit.com/r/dogs"},
+		{"dogs", model.NewPostSort(model.SortNew, ""), "", "https://old.reddit.com/r/dogs/new"},
+		{"dogs", model.NewPostSort(model.SortRising, model.TimeframeWeek), "", "https://old.reddit.com/r/dogs/rising"},
+		{"dogs", model.NewPostSort(model.SortTop, model.TimeframeWeek), "", "https://old.reddit.com/r/dogs/top?t=week"},
+		{"", model.NewPostSort(model.SortControversial, model.TimeframeAll), "t3_abc", "https://old.reddit.com/controversial?after=t3_abc&t=all"},
+	}
+
+	for _, tt := range tests {
+		got := client.BuildPostsUrl(tt.subreddit, tt.sort, tt.after)
+		if got != tt.want {
+			t.Errorf("got %s, want %s with input: subreddit %s, sort %v, after %s", got, tt.want, tt.subreddit, tt.sort, tt.after)
+		}
+	}
+}
+
+func TestBuildSearchUrl(t *testing.T) {
+	client := RedditPostsClient{BaseUrl: testBaseUrl}
+
+	tests := []struct {
+		query     string
+		subreddit string
+		sort      model.PostSort
+		after     string
+		want      string
+	}{
+		{"golden retriever", "", model.DefaultSearchSort(), "", "https://old.reddit.com/search?q=golden+retriever"},
+		{"puppy", "dogs", model.DefaultSearchSort(), "", "https://old.reddit.com/r/dogs/search?q=puppy&restrict_sr=on"},
+		{"puppy", "", model.NewSearchSort(model.SortNew, model.TimeframeWeek), "", "https://old.reddit.com/search?q=puppy&sort=new&t=week"},
+		{"puppy", "dogs", model.NewSearchSort(model.SortTop, model.TimeframeAll), "t3_abc", "https://old.reddit.com/r/dogs/search?after=t3_abc&q=puppy&restrict_sr=on&sort=top&t=all"},
+	}
+
+	for _, tt := range tests {
+		got := client.BuildSearchUrl(tt.query, tt.subreddit, tt.sort, tt.after)
+		if got != tt.want {
+			t.Errorf("got %s, want %s with input: query %s, subreddit %s, sort %v, after %s", got, tt.want, tt.query, tt.subreddit, tt.sort, tt.after)
+		}
+	}
+}
+
+func TestBuildUserUrl(t *testing.T) {
+	client := RedditPostsClient{BaseUrl: testBaseUrl}
+
+	tests := []struct {
+		user  string
+		sort  model.PostSort
+		after string
+		want  string
+	}{
+		{"doglover", model.DefaultUserSort(), "", "https://old.reddit.com/user/doglover"},
+		{"doglover", model.NewPostSort(model.SortHot, ""), "t1_abc", "https://old.reddit.com/user/doglover?after=t1_abc&sort=hot"},
+		{"doglover", model.NewPostSort(model.SortTop, model.TimeframeYear), "", "https://old.reddit.com/user/doglover?sort=top&t=year"},
+	}
+
+	for _, tt := range tests {
+		got := client.BuildUserUrl(tt.user, tt.sort, tt.after)
+		if got != tt.want {
+			t.Errorf("got %s, want %s with input: user %s, sort %v, after %s", got, tt.want, tt.user, tt.sort, tt.after)
+		}
+	}
+}
+
+func TestStalePostsRevalidation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {"id": "fresh", "title": "fresh post"}}]}}`)
+	}))
+	defer server.Close()
+
+	client := RedditPostsClient{
+		BaseUrl:    server.URL,
+		ServerType: "json",
+		CacheTtl:   time.Hour,
+		Client:     server.Client(),
+		Cache:      cache.NewFilePostsCache(t.TempDir()),
+		Parser:     JsonPostsParser{server.URL},
+	}
+
+	ctx := context.Background()
+	sort := model.DefaultPostSort()
+	expired := model.Posts{
+		Posts:  []model.Post{{Id: "stale", PostTitle: "stale post"}},
+		Expiry: time.Now().Add(-time.Minute),
+	}
+	client.Cache.Put(ctx, expired, client.BuildPostsUrl("dogs", sort, ""))
+
+	// Expired posts are returned right away instead of waiting on the server
+	posts, err := client.GetSubredditPosts(ctx, "dogs", sort, "")
+	if err != nil {
+		t.Fatalf("unexpected error getting stale posts: %v", err)
+	}
+
+	if !posts.Stale {
+		t.Errorf("expected expired posts to be marked stale")
+	}
+	if got := posts.Posts[0].Id; got != "stale" {
+		t.Errorf("got %s, want stale post", got)
+	}
+
+	posts, err = client.RevalidatePosts(ctx, posts)
+	if err != nil {
+		t.Fatalf("unexpected error revalidating posts: %v", err)
+	}
+
+	if posts.Stale {
+		t.Errorf("expected revalidated posts to not be stale")
+	}
+	if got := posts.Subreddit; got != "dogs" {
+		t.Errorf("got subreddit %s, want dogs", got)
+	}
+	if got := posts.Posts[0].Id; got != "fresh" {
+		t.Errorf("got %s, want fresh post", got)
+	}
+
+	// Revalidated posts replace the expired cache entry
+	posts, err = client.GetSubredditPosts(ctx, "dogs", sort, "")
+	if err != nil {
+		t.Fatalf("unexpected error getting cached posts: %v", err)
+	}
+
+	if posts.Stale {
+		t.Errorf("expected revalidated posts to not be stale")
+	}
+	if got := posts.Posts[0].Id; got != "fresh" {
+		t.Errorf("got %s, want fresh post from cache", got)
+	}
+}
+
+func TestEmptyPosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": []}}`)
+	}))
+	defer server.Close()
+
+	client := RedditPostsClient{
+		BaseUrl:    server.URL,
+		ServerType: "json",
+		CacheTtl:   time.Hour,
+		Client:     server.Client(),
+		Cache:      cache.NewFilePostsCache(t.TempDir()),
+		Parser:     JsonPostsParser{server.URL},
+	}
+
+	ctx := context.Background()
+	sort := model.DefaultPostSort()
+
+	// Users that have not posted anything have an empty page rather than a missing one
+	posts, err := client.GetUserPosts(ctx, "quietuser", sort, "")
+	if err != nil {
+		t.Fatalf("unexpected error getting empty user posts: %v", err)
+	}
+	if got := len(posts.Posts); got != 0 {
+		t.Errorf("got %d posts, want none", got)
+	}
+	if got := posts.User; got != "quietuser" {
+		t.Errorf("got user %s, want quietuser", got)
+	}
+
+	if _, err := client.GetSubredditPosts(ctx, "notasubreddit", sort, ""); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("expected empty subreddit to not be found, got %v", err)
+	}
+}
+
+func TestOfflinePosts(t *testing.T) {
+	client := RedditPostsClient{
+		BaseUrl: testBaseUrl,
+		Cache:   cache.NewFilePostsCache(t.TempDir()),
+		Offline: common.NewOfflineMode(true),
+	}
+
+	ctx := context.Background()
+	sort := model.DefaultPostSort()
+	expired := model.Posts{
+		Posts:  []model.Post{{Id: "cached", PostTitle: "cached post"}},
+		Expiry: time.Now().Add(-24 * time.Hour),
+	}
+	client.Cache.Put(ctx, expired, client.BuildPostsUrl("dogs", sort, ""))
+
+	// Expired posts are served as is, there is no server to refresh them from
+	posts, err := client.GetSubredditPosts(ctx, "dogs", sort, "")
+	if err != nil {
+		t.Fatalf("unexpected error getting offline posts: %v", err)
+	}
+
+	if posts.Stale {
+		t.Errorf("expected offline posts to not be revalidated")
+	}
+	if got := posts.Posts[0].Id; got != "cached" {
+		t.Errorf("got %s, want cached post", got)
+	}
+
+	if _, err := client.GetSubredditPosts(ctx, "cats", sort, ""); !errors.Is(err, common.ErrOffline) {
+		t.Errorf("expected uncached posts to not be available offline, got %v", err)
+	}
+}
+
+func TestNsfwAndSpoilerPolicies(t *testing.T) {
+	posts := model.Posts{
+		Posts: []model.Post{
+			{Id: "nsfw", Nsfw: true},
+			{Id: "spoiler", Spoiler: true},
+			{Id: "both", Nsfw: true, Spoiler: true},
+			{Id: "regular"},
+		},
+	}
+
+	tests := []struct {
+		nsfwPolicy    string
+		spoilerPolicy string
+		want          []string
+	}{
+		{model.ShowPosts, model.ShowPosts, []string{"nsfw", "spoiler", "both", "regular"}},
+		{model.TagPosts, model.TagPosts, []string{"nsfw [NSFW]", "spoiler [spoiler]", "both [NSFW] [spoiler]", "regular"}},
+		{model.HidePosts, model.TagPosts, []string{"spoiler [spoiler]", "regular"}},
+		{model.ShowPosts, model.HidePosts, []string{"nsfw", "regular"}},
+		{model.TagPosts, model.MaskPosts, []string{"nsfw [NSFW]", "spoiler [spoiler] (masked)", "both [NSFW] [spoiler] (masked)", "regular"}},
+		{model.MaskPosts, model.ShowPosts, []string{"nsfw [NSFW] (masked)", "spoiler", "both [NSFW] (masked)", "regular"}},
+	}
+
+	for _, tt := range tests {
+		client := RedditPostsClient{NsfwPolicy: tt.nsfwPolicy, SpoilerPolicy: tt.spoilerPolicy}
+
+		var got []string
+		for _, post := range client.filterPosts(posts).Posts {
+			name := post.Id
+			for _, tag := range post.Tags {
+				name += fmt.Sprintf(" [%s]", tag)
+			}
+			if post.Masked {
+				name += " (masked)"
+			}
+			got = append(got, name)
+		}
+
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("got %v, want %v with nsfw policy %s and spoiler policy %s", got, tt.want, tt.nsfwPolicy, tt.spoilerPolicy)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package posts
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSearchPage = `<html><body>
+<div class="search-result search-result-link has-thumbnail" data-fullname="t3_abc">
+	<header class="search-result-header"><a href="https://old.reddit.com/r/dogs/comments/abc/puppy/" class="search-title may-blank">Puppy training tips</a></header>
+	<div class="search-result-meta">
+		<span class="search-score">1,234 points</span>
+		<a href="https://old.reddit.com/r/dogs/comments/abc/puppy/" class="search-comments may-blank">56 comments</a>
+		<span class="search-time">submitted <time title="Mon Mar 3" datetime="2025-03-03T10:00:00+00:00">2 days ago</time> by <a href="https://old.reddit.com/user/doglover" class="author may-blank">doglover</a></span>
+		<span>to <a href="https://old.reddit.com/r/dogs/" class="search-subreddit-link may-blank">r/dogs</a></span>
+	</div>
+	<div class="search-result-footer"><a href="https://example.com/puppy" class="search-link may-blank">https://example.com/puppy</a></div>
+</div>
+<div class="nav-buttons"><span class="nextprev">view more: <a href="https://old.reddit.com/search?q=puppy&amp;after=t3_abc" rel="nofollow next">next ›</a></span></div>
+</body></html>`
+
+func TestOldRedditParseSearchResults(t *testing.T) {
+	posts, err := OldRedditPostsParser{}.ParsePosts(strings.NewReader(testSearchPage))
+	if err != nil {
+		t.Fatalf("could not parse search results: %v", err)
+	}
+
+	if len(posts.Posts) != 1 {
+		t.Fatalf("expected 1 post but got %d", len(posts.Posts))
+	}
+
+	post := posts.Posts[0]
+	tests := []struct {
+		field string
+		want  string
+		got   string
+	}{
+		{"Id", "abc", post.Id},
+		{"PostTitle", "Puppy training tips", post.PostTitle},
+		{"PostUrl", "https://example.com/puppy", post.PostUrl},
+		{"CommentsUrl", "https://old.reddit.com/r/dogs/comments/abc/puppy/", post.CommentsUrl},
+		{"Author", "doglover", post.Author},
+		{"Subreddit", "r/dogs", post.Subreddit},
+		{"FriendlyDate", "2 days ago", post.FriendlyDate},
+		{"TotalComments", "56", post.TotalComments},
+		{"TotalLikes", "1,234", post.TotalLikes},
+		{"After", "t3_abc", posts.After},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.field, tt.got, tt.want)
+		}
+	}
+}
+
+const testUserPage = `<html><body>
+<div class="thing id-t1_c1 comment" data-fullname="t1_c1" data-author="doglover" data-subreddit-prefixed="r/dogs" data-permalink="/r/dogs/comments/abc/puppy/c1/">
+	<p class="parent"><a class="title" href="https://example.com/puppy">Puppy training tips</a></p>
+	<div class="entry">
+		<p class="tagline"><a class="author">doglover</a> <span class="score unvoted" title="42">42 points</span> <time class="live-timestamp">3 hours ago</time></p>
+		<form><div class="usertext-body"><div class="md"><p>Treats work   <em>really</em> well</p></div></div></form>
+		<ul class="flat-list buttons"><li class="first"><a href="https://old.reddit.com/r/dogs/comments/abc/puppy/c1/" data-event-action="permalink" class="bylink">permalink</a></li></ul>
+	</div>
+</div>
+</body></html>`
+
+func TestOldRedditParseUserComments(t *testing.T) {
+	posts, err := OldRedditPostsParser{testBaseUrl}.ParsePosts(strings.NewReader(testUserPage))
+	if err != nil {
+		t.Fatalf("could not parse user page: %v", err)
+	}
+
+	if len(posts.Posts) != 1 {
+		t.Fatalf("expected 1 post but got %d", len(posts.Posts))
+	}
+
+	post := posts.Posts[0]
+	if !post.IsComment {
+		t.Errorf("expected user comment to be marked as a comment")
+	}
+
+	tests := []struct {
+		field string
+		want  string
+		got   string
+	}{
+		{"PostTitle", "Treats work really well", post.PostTitle},
+		{"LinkTitle", "Puppy training tips", post.LinkTitle},
+		{"CommentsUrl", "https://old.reddit.com/r/dogs/comments/abc/puppy/c1/", post.CommentsUrl},
+		{"Author", "doglover", post.Author},
+		{"Subreddit", "r/dogs", post.Subreddit},
+		{"FriendlyDate", "3 hours ago", post.FriendlyDate},
+		{"TotalLikes", "42", post.TotalLikes},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.field, tt.got, tt.want)
+		}
+	}
+}
+
+const testNsfwPage = `<html><body>
+<div class="thing id-t3_a1 link over18" data-fullname="t3_a1" data-nsfw="true" data-spoiler="false">
+	<a class="title" href="https://old.reddit.com/r/dogs/comments/a1/">Nsfw post</a>
+</div>
+<div class="thing id-t3_a2 link" data-fullname="t3_a2" data-nsfw="false" data-spoiler="true">
+	<a class="title" href="https://old.reddit.com/r/dogs/comments/a2/">Spoiler post</a>
+</div>
+<div class="thing id-t3_a3 link" data-fullname="t3_a3" data-nsfw="false" data-spoiler="false">
+	<a class="title" href="https://old.reddit.com/r/dogs/comments/a3/">Regular post</a>
+</div>
+</body></html>`
+
+func TestOldRedditParseFlags(t *testing.T) {
+	posts, err := OldRedditPostsParser{testBaseUrl}.ParsePosts(strings.NewReader(testNsfwPage))
+	if err != nil {
+		t.Fatalf("could not parse posts: %v", err)
+	}
+
+	if len(posts.Posts) != 3 {
+		t.Fatalf("expected 3 posts but got %d", len(posts.Posts))
+	}
+
+	tests := []struct {
+		nsfw    bool
+		spoiler bool
+	}{
+		{true, false},
+		{false, true},
+		{false, false},
+	}
+
+	for i, tt := range tests {
+		post := posts.Posts[i]
+		if post.Nsfw != tt.nsfw || post.Spoiler != tt.spoiler {
+			t.Errorf("%s: got nsfw %t spoiler %t, want nsfw %t spoiler %t", post.PostTitle, post.Nsfw, post.Spoiler, tt.nsfw, tt.spoiler)
+		}
+	}
+}
+
+const testRedlibPage = `<html><body>
+<div class="post" id="1g2h3i4">
+	<p class="post_header">
+		<a class="post_subreddit" href="/r/golang">r/golang</a>
+		<span class="dot">&bull;</span>
+		<a class="post_author" href="/u/gopher">u/gopher</a>
+		<span class="dot">&bull;</span>
+		<span class="created" title="Mar 3 2025, 10:00:00 UTC">3h ago</span>
+	</p>
+	<h2 class="post_title">
+		<a href="/r/golang/comments/1g2h3i4/go_124_is_released/">Go 1.24 is released</a>
+	</h2>
+	<div class="post_footer">
+		<a href="/r/golang/comments/1g2h3i4/go_124_is_released/" class="post_comments" title="87 comments">87 comments</a>
+	</div>
+</div>
+</body></html>`
+
+func TestRedlibParsePosts(t *testing.T) {
+	posts, err := RedlibParser{"https://redlib.example.com"}.ParsePosts(strings.NewReader(testRedlibPage))
+	if err != nil {
+		t.Fatalf("could not parse posts: %v", err)
+	}
+
+	if len(posts.Posts) != 1 {
+		t.Fatalf("expected 1 post but got %d", len(posts.Posts))
+	}
+
+	post := posts.Posts[0]
+	tests := []struct {
+		field string
+		want  string
+		got   string
+	}{
+		{"Id", "1g2h3i4", post.Id},
+		{"Fullname", "t3_1g2h3i4", post.Fullname()},
+		{"PostTitle", "Go 1.24 is released", post.PostTitle},
+		{"CommentsUrl", "https://redlib.example.com/r/golang/comments/1g2h3i4/go_124_is_released/", post.CommentsUrl},
+		{"Author", "u/gopher", post.Author},
+		{"Subreddit", "r/golang", post.Subreddit},
+		{"TotalComments", "87 comments", post.TotalComments},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.field, tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package render
+
+import (
+	"log/slog"
+	"reddittui/client/common"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+const (
+	darkCodeStyle  = "catppuccin-mocha"
+	lightCodeStyle = "catppuccin-latte"
+)
+
+// Code is boxed and highlighted but never wrapped, lines wider than the text are scrolled instead
+func (r Renderer) codeBlock(node common.HtmlNode) string {
+	code := strings.Trim(textContent(node), "\n")
+	if len(strings.TrimSpace(code)) == 0 {
+		return ""
+	}
+
+	lexer := codeLexer(node, code)
+	box := codeBoxStyle.Render(highlight(lexer, code))
+	if lexer == nil {
+		return box
+	}
+
+	return labelBox(box, strings.ToLower(lexer.Config().Name))
+}
+
+// Lexer for the language named by a language-* class, which some markdown renderers add to code
+// blocks, or guessed from the code itself. Nil when the language is unknown
+func codeLexer(node common.HtmlNode, code string) chroma.Lexer {
+	var classes []string
+	for n := range node.Descendants() {
+		if n.Data == "code" {
+			classes = append(classes, common.HtmlNode{Node: n}.Classes()...)
+		}
+	}
+
+	for _, class := range append(node.Classes(), classes...) {
+		if language, ok := strings.CutPrefix(class, "language-"); ok {
+			if lexer := lexers.Get(language); lexer != nil {
+				return lexer
+			}
+		} else if language, ok := strings.CutPrefix(class, "lang-"); ok {
+			if lexer := lexers.Get(language); lexer != nil {
+				return lexer
+			}
+		}
+	}
+
+	return lexers.Analyse(code)
+}
+
+// Highlight the code in the colors the terminal supports, leaving it plain when it supports none
+func highlight(lexer chroma.Lexer, code string) string {
+	var formatter chroma.Formatter
+	switch lipgloss.ColorProfile() {
+	case termenv.TrueColor:
+		formatter = formatters.TTY16m
+	case termenv.ANSI256:
+		formatter = formatters.TTY256
+	case termenv.ANSI:
+		formatter = formatters.TTY16
+	}
+
+	if lexer == nil || formatter == nil {
+		return code
+	}
+
+	style := styles.Get(lightCodeStyle)
+	if lipgloss.HasDarkBackground() {
+		style = styles.Get(darkCodeStyle)
+	}
+
+	tokens, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		slog.Debug("Error highlighting code", "language", lexer.Config().Name, "error", err)
+		return code
+	}
+
+	var sb strings.Builder
+	if err := formatter.Format(&sb, style, tokens); err != nil {
+		slog.Debug("Error highlighting code", "language", lexer.Config().Name, "error", err)
+		return code
+	}
+
+	return sb.String()
+}
+
+// Write the language in the top border of the box when it fits
+func labelBox(box, label string) string {
+	top, rest, _ := strings.Cut(box, "\n")
+	border := lipgloss.RoundedBorder()
+	fill := lipgloss.Width(top) - lipgloss.Width(label) - 5
+	if fill < 0 {
+		return box
+	}
+
+	top = codeBorderStyle.Render(border.TopLeft+border.Top+" ") +
+		codeLabelStyle.Render(label) +
+		codeBorderStyle.Render(" "+strings.Repeat(border.Top, fill)+border.TopRight)
+	return top + "\n" + rest
+}
@@ -0,0 +1,499 @@
+package render
+
+import (
+	"fmt"
+	"log/slog"
+	"reddittui/client/common"
+	"reddittui/utils"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/muesli/reflow/wrap"
+	"golang.org/x/net/html"
+)
+
+const (
+	// Nested lists and quotes are never squeezed narrower than this
+	minWidth = 10
+
+	// Table columns are shrunk down to this width before the table is left wider than the text
+	minColumnWidth = 4
+
+	// Width of horizontal rules in unwrapped text
+	defaultRuleWidth = 20
+)
+
+var whitespaceRegex = regexp.MustCompile(`\s+`)
+
+var (
+	blockTags = []string{
+		"html", "body", "div", "form", "section", "article", "header", "footer", "main", "details", "summary",
+		"p", "h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "li", "blockquote", "pre", "hr", "table",
+	}
+
+	// Parts of the page around the markdown that should not be shown
+	hiddenTags = []string{"head", "script", "style", "noscript", "input", "textarea", "button", "select"}
+
+	// Bullets of nested lists, starting over after the last one
+	bullets = []string{"•", "◦", "▪"}
+
+	superscripts = map[rune]rune{
+		'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴', '5': '⁵', '6': '⁶', '7': '⁷', '8': '⁸', '9': '⁹',
+		'+': '⁺', '-': '⁻', '=': '⁼', '(': '⁽', ')': '⁾', ' ': ' ',
+		'a': 'ᵃ', 'b': 'ᵇ', 'c': 'ᶜ', 'd': 'ᵈ', 'e': 'ᵉ', 'f': 'ᶠ', 'g': 'ᵍ', 'h': 'ʰ', 'i': 'ⁱ', 'j': 'ʲ',
+		'k': 'ᵏ', 'l': 'ˡ', 'm': 'ᵐ', 'n': 'ⁿ', 'o': 'ᵒ', 'p': 'ᵖ', 'r': 'ʳ', 's': 'ˢ', 't': 'ᵗ', 'u': 'ᵘ',
+		'v': 'ᵛ', 'w': 'ʷ', 'x': 'ˣ', 'y': 'ʸ', 'z': 'ᶻ',
+	}
+)
+
+// Renders the html reddit generates from markdown, i.e. the div.md of a post or comment, as styled
+// terminal text
+type Renderer struct {
+	// Width to wrap text to, text is left unwrapped when it is 0
+	Width int
+
+	// Show spoilers instead of hiding them behind blocks
+	RevealSpoilers bool
+}
+
+type state struct {
+	width     int
+	listDepth int
+}
+
+// Render an html fragment, such as the body_html of a comment or html saved with Source
+func (r Renderer) RenderHtml(source string) string {
+	doc, err := html.Parse(strings.NewReader(source))
+	if err != nil {
+		slog.Debug("Error parsing html", "error", err)
+		return ""
+	}
+
+	return r.Render(common.HtmlNode{Node: doc})
+}
+
+func (r Renderer) Render(node common.HtmlNode) string {
+	return strings.Join(r.blocks(node, state{width: r.Width}), "\n\n")
+}
+
+// Html of the node, kept so it can be rendered again once the width it is shown at is known
+func Source(node common.HtmlNode) string {
+	var sb strings.Builder
+	if err := html.Render(&sb, node.Node); err != nil {
+		slog.Debug("Error rendering html", "error", err)
+		return ""
+	}
+
+	return sb.String()
+}
+
+// Render the children of node, grouping runs of inline content into paragraphs
+func (r Renderer) blocks(node common.HtmlNode, s state) []string {
+	var (
+		blocks []string
+		inline strings.Builder
+	)
+
+	flush := func() {
+		if paragraph := r.paragraph(inline.String(), s.width); len(paragraph) > 0 {
+			blocks = append(blocks, paragraph)
+		}
+		inline.Reset()
+	}
+
+	for c := range node.ChildNodes() {
+		child := common.HtmlNode{Node: c}
+		if !isBlock(child) {
+			r.inline(child, &inline)
+			continue
+		}
+
+		flush()
+		if block := r.block(child, s); len(block) > 0 {
+			blocks = append(blocks, block)
+		}
+	}
+
+	flush()
+	return blocks
+}
+
+func (r Renderer) block(node common.HtmlNode, s state) string {
+	switch tag := node.Tag(); tag {
+	case "p":
+		return r.paragraph(r.inlineText(node), s.width)
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		style := headingStyle
+		if tag == "h1" {
+			style = titleHeadingStyle
+		}
+		return styleLines(style, r.paragraph(r.inlineText(node), s.width))
+	case "ul", "ol":
+		return r.list(node, s)
+	case "blockquote":
+		return r.quote(node, s)
+	case "pre":
+		return r.codeBlock(node)
+	case "hr":
+		width := s.width
+		if width <= 0 {
+			width = defaultRuleWidth
+		}
+		return ruleStyle.Render(strings.Repeat("─", width))
+	case "table":
+		return r.table(node, s)
+	}
+
+	if slices.Contains(hiddenTags, node.Tag()) {
+		return ""
+	}
+
+	return strings.Join(r.blocks(node, s), "\n\n")
+}
+
+// Tidy up the whitespace left around inline elements and wrap the text
+func (r Renderer) paragraph(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+
+	text = strings.Trim(strings.Join(lines, "\n"), "\n")
+	if len(strings.TrimSpace(text)) == 0 {
+		return ""
+	}
+
+	return wrapText(text, width)
+}
+
+// Items are indented past their markers, with bullets changing at each level of nesting
+func (r Renderer) list(node common.HtmlNode, s state) string {
+	var (
+		items   []common.HtmlNode
+		ordered = node.Tag() == "ol"
+		start   = 1
+	)
+
+	for item := range node.FindChildren("li") {
+		items = append(items, item)
+	}
+
+	if n, err := strconv.Atoi(node.GetAttr("start")); err == nil {
+		start = n
+	}
+
+	markerWidth := 2
+	if ordered {
+		markerWidth = len(strconv.Itoa(start+len(items)-1)) + 2
+	}
+
+	inner := state{width: shrink(s.width, markerWidth), listDepth: s.listDepth + 1}
+	rendered := make([]string, len(items))
+	for i, item := range items {
+		marker := bullets[s.listDepth%len(bullets)]
+		if ordered {
+			marker = fmt.Sprintf("%d.", start+i)
+		}
+
+		marker = listMarkerStyle.Render(fmt.Sprintf("%-*s", markerWidth, marker))
+		rendered[i] = hang(strings.Join(r.blocks(item, inner), "\n"), marker, strings.Repeat(" ", markerWidth))
+	}
+
+	return strings.Join(rendered, "\n")
+}
+
+func (r Renderer) quote(node common.HtmlNode, s state) string {
+	inner := s
+	inner.width = shrink(s.width, 2)
+
+	bar := quoteBarStyle.Render("│")
+	lines := strings.Split(strings.Join(r.blocks(node, inner), "\n\n"), "\n")
+	for i, line := range lines {
+		if len(line) == 0 {
+			lines[i] = bar
+		} else {
+			lines[i] = bar + " " + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Columns are separated by lines, with the widest columns shrunk and their cells wrapped until the
+// table fits the width
+func (r Renderer) table(node common.HtmlNode, s state) string {
+	var (
+		rows       [][]string
+		aligns     []lipgloss.Position
+		headerRows int
+	)
+
+	for row := range node.FindDescendants("tr") {
+		var (
+			cells    []string
+			isHeader = row.Parent != nil && row.Parent.Data == "thead"
+		)
+
+		for cell := range row.ChildNodes() {
+			cellNode := common.HtmlNode{Node: cell}
+			if !cellNode.TagEquals("th") && !cellNode.TagEquals("td") {
+				continue
+			}
+
+			if len(aligns) <= len(cells) {
+				aligns = append(aligns, cellAlign(cellNode))
+			}
+
+			isHeader = isHeader || cellNode.TagEquals("th")
+			cells = append(cells, r.paragraph(r.inlineText(cellNode), 0))
+		}
+
+		if isHeader && headerRows == len(rows) {
+			headerRows++
+		}
+		rows = append(rows, cells)
+	}
+
+	widths := make([]int, len(aligns))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+
+	if s.width > 0 {
+		total := 3 * (len(widths) - 1)
+		for _, width := range widths {
+			total += width
+		}
+
+		for total > s.width {
+			i := slices.Index(widths, slices.Max(widths))
+			if widths[i] <= minColumnWidth {
+				break
+			}
+
+			widths[i]--
+			total--
+		}
+	}
+
+	var (
+		lines     []string
+		separator = tableBorderStyle.Render(" │ ")
+	)
+
+	for i, row := range rows {
+		cellLines := make([][]string, len(widths))
+		height := 1
+		for j := range widths {
+			cell := ""
+			if j < len(row) {
+				cell = row[j]
+			}
+			if i < headerRows {
+				cell = tableHeaderStyle.Render(cell)
+			}
+
+			cellLines[j] = strings.Split(wrapText(cell, widths[j]), "\n")
+			height = max(height, len(cellLines[j]))
+		}
+
+		for k := range height {
+			pieces := make([]string, len(widths))
+			for j, width := range widths {
+				line := ""
+				if k < len(cellLines[j]) {
+					line = cellLines[j][k]
+				}
+				pieces[j] = pad(line, width, aligns[j])
+			}
+			lines = append(lines, strings.TrimRight(strings.Join(pieces, separator), " "))
+		}
+
+		if i == headerRows-1 {
+			rules := make([]string, len(widths))
+			for j, width := range widths {
+				rules[j] = strings.Repeat("─", width)
+			}
+			lines = append(lines, tableBorderStyle.Render(strings.Join(rules, "─┼─")))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (r Renderer) inline(node common.HtmlNode, sb *strings.Builder) {
+	if node.Type == html.TextNode {
+		text := whitespaceRegex.ReplaceAllString(node.Data, " ")
+		if written := sb.String(); len(written) == 0 || strings.HasSuffix(written, " ") || strings.HasSuffix(written, "\n") {
+			text = strings.TrimLeft(text, " ")
+		}
+		sb.WriteString(text)
+		return
+	} else if node.Type != html.ElementNode {
+		return
+	}
+
+	switch node.Tag() {
+	case "a":
+		sb.WriteString(common.RenderAnchor(node))
+	case "br":
+		sb.WriteString("\n")
+	case "strong", "b":
+		sb.WriteString(boldStyle.Render(r.inlineText(node)))
+	case "em", "i":
+		sb.WriteString(italicStyle.Render(r.inlineText(node)))
+	case "del", "s", "strike":
+		sb.WriteString(strikethroughStyle.Render(r.inlineText(node)))
+	case "code":
+		sb.WriteString(inlineCodeStyle.Render(textContent(node)))
+	case "sup":
+		sb.WriteString(superscript(r.inlineText(node)))
+	case "span":
+		if isSpoiler(node) {
+			sb.WriteString(r.spoiler(node))
+		} else {
+			r.inlineChildren(node, sb)
+		}
+	default:
+		r.inlineChildren(node, sb)
+	}
+}
+
+func (r Renderer) inlineChildren(node common.HtmlNode, sb *strings.Builder) {
+	for c := range node.ChildNodes() {
+		r.inline(common.HtmlNode{Node: c}, sb)
+	}
+}
+
+func (r Renderer) inlineText(node common.HtmlNode) string {
+	var sb strings.Builder
+	r.inlineChildren(node, &sb)
+	return sb.String()
+}
+
+// Spoilers are hidden behind blocks as wide as their text, so they wrap the same way once revealed
+func (r Renderer) spoiler(node common.HtmlNode) string {
+	text := r.inlineText(node)
+	if r.RevealSpoilers {
+		return styleLines(revealedSpoilerStyle, text)
+	}
+
+	return styleLines(spoilerStyle, utils.Redact(ansi.Strip(text)))
+}
+
+// Old reddit and the json api mark spoilers with span.md-spoiler-text, redlib with span.spoiler
+func isSpoiler(node common.HtmlNode) bool {
+	return node.ClassContains("md-spoiler-text") || node.ClassContains("spoiler")
+}
+
+func isBlock(node common.HtmlNode) bool {
+	return node.Type == html.ElementNode && (slices.Contains(blockTags, node.Tag()) || slices.Contains(hiddenTags, node.Tag()))
+}
+
+// All of the text in the node, with its whitespace untouched
+func textContent(node common.HtmlNode) string {
+	var sb strings.Builder
+	for n := range node.Descendants() {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+	}
+
+	return sb.String()
+}
+
+func wrapText(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+
+	// Words longer than the width, such as urls, are broken up as well
+	return wrap.String(wordwrap.String(text, width), width)
+}
+
+// Width left after indenting by n columns
+func shrink(width, n int) int {
+	if width <= 0 {
+		return 0
+	}
+
+	return max(width-n, minWidth)
+}
+
+// Style lines separately so lipgloss does not pad them to the same width
+func styleLines(style lipgloss.Style, text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = style.Render(line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Prefix the first line of text with first and the lines after it with rest
+func hang(text, first, rest string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if i == 0 {
+			lines[i] = first + line
+		} else {
+			lines[i] = rest + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func pad(text string, width int, align lipgloss.Position) string {
+	gap := max(width-lipgloss.Width(text), 0)
+	switch align {
+	case lipgloss.Right:
+		return strings.Repeat(" ", gap) + text
+	case lipgloss.Center:
+		return strings.Repeat(" ", gap/2) + text + strings.Repeat(" ", gap-gap/2)
+	default:
+		return text + strings.Repeat(" ", gap)
+	}
+}
+
+// Reddit aligns columns with the align attribute, redlib with inline styles
+func cellAlign(cell common.HtmlNode) lipgloss.Position {
+	align := cell.GetAttr("align")
+	if style := strings.ReplaceAll(cell.GetAttr("style"), " ", ""); strings.Contains(style, "text-align:") {
+		align, _, _ = strings.Cut(strings.SplitN(style, "text-align:", 2)[1], ";")
+	}
+
+	switch align {
+	case "right":
+		return lipgloss.Right
+	case "center":
+		return lipgloss.Center
+	default:
+		return lipgloss.Left
+	}
+}
+
+// Terminals have no small text, so superscript is written with superscript characters when there is
+// one for each character, and with reddit's ^(...) syntax otherwise
+func superscript(text string) string {
+	var sb strings.Builder
+	for _, c := range text {
+		s, ok := superscripts[c]
+		if !ok {
+			if strings.Contains(text, " ") {
+				return fmt.Sprintf("^(%s)", text)
+			}
+			return "^" + text
+		}
+		sb.WriteRune(s)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,182 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		width  int
+		want   string
+	}{
+		{
+			name:   "paragraphs",
+			source: "<div class=\"md\"><p>First\n  paragraph</p>\n<p>Second<br>line</p></div>",
+			want:   "First paragraph\n\nSecond\nline",
+		},
+		{
+			name:   "wrapped paragraph",
+			source: "<p>The quick brown fox jumps over the lazy dog</p>",
+			width:  16,
+			want:   "The quick brown\nfox jumps over\nthe lazy dog",
+		},
+		{
+			name:   "inline elements",
+			source: "<p><strong>bold</strong>, <em>italic</em>, <del>struck</del> and <code>a  b</code></p>",
+			want:   "bold, italic, struck and a  b",
+		},
+		{
+			name:   "superscript",
+			source: "<p>x<sup>2</sup> and <sup>Hello world</sup></p>",
+			want:   "x² and ^(Hello world)",
+		},
+		{
+			name:   "links",
+			source: "<p>See <a href=\"https://example.com\">the docs</a> or <a href=\"/r/golang\">r/golang</a></p>",
+			want:   "See the docs https://example.com or r/golang",
+		},
+		{
+			name:   "heading",
+			source: "<h2>Update</h2><p>Text</p>",
+			want:   "Update\n\nText",
+		},
+		{
+			name:   "nested lists",
+			source: "<ul><li>one</li><li>two<ul><li>nested item that wraps</li></ul></li></ul>",
+			width:  16,
+			want:   "• one\n• two\n  ◦ nested item\n    that wraps",
+		},
+		{
+			name:   "ordered list",
+			source: "<ol start=\"9\"><li><p>nine</p></li><li><p>ten</p><p>more</p></li></ol>",
+			want:   "9.  nine\n10. ten\n    more",
+		},
+		{
+			name:   "nested blockquotes",
+			source: "<blockquote><p>quoted text</p><blockquote><p>inner</p></blockquote></blockquote>",
+			width:  12,
+			want:   "│ quoted\n│ text\n│\n│ │ inner",
+		},
+		{
+			name:   "code block",
+			source: "<pre><code>if x {\n    return\n}\n</code></pre>",
+			width:  8,
+			want:   "╭────────────╮\n│ if x {     │\n│     return │\n│ }          │\n╰────────────╯",
+		},
+		{
+			name:   "horizontal rule",
+			source: "<p>above</p><hr><p>below</p>",
+			width:  5,
+			want:   "above\n\n─────\n\nbelow",
+		},
+		{
+			name: "table",
+			source: "<table><thead><tr><th>Name</th><th align=\"right\">Age</th></tr></thead>" +
+				"<tbody><tr><td>Kini</td><td align=\"right\">40</td></tr><tr><td>Eli</td><td align=\"right\">3</td></tr></tbody></table>",
+			want: "Name │ Age\n─────┼────\nKini │  40\nEli  │   3",
+		},
+		{
+			name: "table wider than the text",
+			source: "<table><tr><th>Name</th><th>Notes</th></tr>" +
+				"<tr><td>Kini</td><td>Likes long walks</td></tr></table>",
+			width: 15,
+			want:  "Name │ Notes\n─────┼─────────\nKini │ Likes\n     │ long\n     │ walks",
+		},
+		{
+			name:   "form controls",
+			source: "<form class=\"usertext\"><input type=\"hidden\" name=\"thing_id\" value=\"t1_x\"><div class=\"md\"><p>Comment</p></div></form>",
+			want:   "Comment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Renderer{Width: tt.width}.RenderHtml(tt.source)
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderSpoilers(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		reveal bool
+		want   string
+	}{
+		{
+			name:   "old reddit spoiler",
+			source: "<p>The butler <span class=\"md-spoiler-text\">did it</span>.</p>",
+			want:   "The butler ███ ██.",
+		},
+		{
+			name:   "redlib spoiler",
+			source: "<p>The butler <span class=\"spoiler\">did <em>it</em></span>.</p>",
+			want:   "The butler ███ ██.",
+		},
+		{
+			name:   "revealed spoiler",
+			source: "<p>The butler <span class=\"md-spoiler-text\">did <em>it</em></span>.</p>",
+			reveal: true,
+			want:   "The butler did it.",
+		},
+		{
+			name:   "other spans",
+			source: "<p><span class=\"highlight\">shown</span></p>",
+			want:   "shown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Renderer{RevealSpoilers: tt.reveal}.RenderHtml(tt.source)
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderWidth(t *testing.T) {
+	source := "<ul><li><blockquote><p>" + strings.Repeat("word ", 40) + "</p></blockquote></li></ul>"
+	for _, line := range strings.Split(Renderer{Width: 30}.RenderHtml(source), "\n") {
+		if width := len([]rune(line)); width > 30 {
+			t.Errorf("line %q is %d wide, want at most 30", line, width)
+		}
+	}
+}
+
+func TestRenderCodeBlock(t *testing.T) {
+	source := "<pre><code class=\"language-go\">fmt.Println(\"a line of code wider than the text\")\n</code></pre>"
+	code := "fmt.Println(\"a line of code wider than the text\")"
+	want := "╭─ go " + strings.Repeat("─", len(code)-3) + "╮\n" +
+		"│ " + code + " │\n" +
+		"╰" + strings.Repeat("─", len(code)+2) + "╯"
+
+	// Code is not wrapped to the width
+	renderer := Renderer{Width: 20}
+	if got := renderer.RenderHtml(source); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	defer lipgloss.SetColorProfile(profile)
+
+	got := Renderer{}.RenderHtml(source)
+	if !strings.Contains(got, "\x1b[") {
+		t.Errorf("expected code to be highlighted, got %q", got)
+	}
+	if !strings.Contains(ansi.Strip(got), "fmt.Println(") {
+		t.Errorf("expected highlighted code to keep its text, got %q", got)
+	}
+}
@@ -0,0 +1,26 @@
+package render
+
+import (
+	"reddittui/components/colors"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	headingStyle         = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Lavender)).Bold(true)
+	titleHeadingStyle    = headingStyle.Underline(true)
+	boldStyle            = lipgloss.NewStyle().Bold(true)
+	italicStyle          = lipgloss.NewStyle().Italic(true)
+	strikethroughStyle   = lipgloss.NewStyle().Strikethrough(true)
+	inlineCodeStyle      = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Pink))
+	codeBorderStyle      = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext))
+	codeBoxStyle         = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(colors.AdaptiveColor(colors.Subtext)).Padding(0, 1)
+	codeLabelStyle       = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext)).Italic(true)
+	quoteBarStyle        = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext))
+	listMarkerStyle      = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext))
+	ruleStyle            = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext)).Faint(true)
+	tableHeaderStyle     = lipgloss.NewStyle().Bold(true)
+	tableBorderStyle     = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext))
+	spoilerStyle         = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext))
+	revealedSpoilerStyle = lipgloss.NewStyle().Underline(true)
+)
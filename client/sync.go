@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+	"reddittui/config"
+	"reddittui/model"
+	"reddittui/utils"
+	"strings"
+)
+
+// Listing downloaded by the sync command, using the sort the tui opens it with
+type SyncTarget struct {
+	Name      string
+	Subreddit string // Empty for the home page
+	Sort      model.PostSort
+}
+
+type SyncResult struct {
+	Pages   int
+	Posts   int
+	Threads int
+}
+
+// Listings configured in the sync section. Feeds are looked up by name, unknown feeds are skipped
+func GetSyncTargets(configuration config.Config) []SyncTarget {
+	var targets []SyncTarget
+	if configuration.Sync.Home {
+		targets = append(targets, SyncTarget{Name: "home", Sort: model.DefaultPostSort()})
+	}
+
+	for _, subreddit := range configuration.Sync.Subreddits {
+		subreddit = strings.TrimPrefix(strings.TrimSpace(subreddit), "r/")
+		if len(subreddit) > 0 {
+			targets = append(targets, SyncTarget{utils.NormalizeSubreddit(subreddit), subreddit, model.DefaultPostSort()})
+		}
+	}
+
+outer:
+	for _, name := range configuration.Sync.Feeds {
+		for _, feedConfig := range configuration.Feeds {
+			feed := model.NewFeed(feedConfig.Name, feedConfig.Subreddits, feedConfig.Sort, feedConfig.Timeframe)
+			if strings.EqualFold(feed.Name, strings.TrimSpace(name)) && len(feed.Subreddits) > 0 {
+				targets = append(targets, SyncTarget{feed.Name, feed.Subreddit(), feed.Sort})
+				continue outer
+			}
+		}
+
+		slog.Warn("Skipping unknown feed in sync configuration", "name", name)
+	}
+
+	return targets
+}
+
+// Download pages of posts for the target, and the comments for the first threads posts, into the cache.
+// Everything is fetched from the server even when cached so it is stored with the client's cache ttl.
+// Threads in seen were already downloaded for another target and are not fetched again
+func (r RedditClient) Sync(ctx context.Context, target SyncTarget, pages, threads int, seen map[string]bool) (SyncResult, error) {
+	var (
+		result SyncResult
+		after  string
+		urls   []string
+	)
+
+	for result.Pages < pages {
+		postsClient := r.postsClient
+		if len(target.Subreddit) == 0 {
+			postsClient = r.homePostsClient()
+		}
+
+		posts, err := postsClient.RefreshPosts(ctx, target.Subreddit, target.Sort, after)
+		if err != nil {
+			return result, err
+		}
+
+		result.Pages++
+		result.Posts += len(posts.Posts)
+		for _, post := range posts.Posts {
+			urls = append(urls, post.CommentsUrl)
+		}
+
+		after = posts.After
+		if len(after) == 0 {
+			break
+		}
+	}
+
+	for _, url := range urls {
+		if result.Threads >= threads {
+			break
+		} else if len(url) == 0 {
+			continue
+		}
+
+		if !seen[url] {
+			if _, err := r.commentsClient.RevalidateComments(ctx, url, ""); err != nil {
+				if ctx.Err() != nil {
+					return result, ctx.Err()
+				}
+
+				slog.Warn("Could not sync comments", "url", url, "error", err)
+				continue
+			}
+		}
+
+		seen[url] = true
+		result.Threads++
+	}
+
+	return result, nil
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"reddittui/config"
+	"reddittui/model"
+	"testing"
+)
+
+func TestGetSyncTargets(t *testing.T) {
+	configuration := config.NewConfig()
+	configuration.Feeds = []config.FeedConfig{
+		{Name: "languages", Subreddits: []string{"golang", "r/rust"}, Sort: "top", Timeframe: "week"},
+		{Name: "empty"},
+	}
+	configuration.Sync.Subreddits = []string{"r/dogs", " ", "cats"}
+	configuration.Sync.Feeds = []string{"Languages", "empty", "missing"}
+
+	want := []SyncTarget{
+		{"home", "", model.DefaultPostSort()},
+		{"r/dogs", "dogs", model.DefaultPostSort()},
+		{"r/cats", "cats", model.DefaultPostSort()},
+		{"languages", "golang+rust", model.NewPostSort(model.SortTop, model.TimeframeWeek)},
+	}
+
+	got := GetSyncTargets(configuration)
+	if len(got) != len(want) {
+		t.Fatalf("got %d sync targets, want %d: %v", len(got), len(want), got)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got[i], want[i])
+		}
+	}
+
+	configuration.Sync.Home = false
+	if got := GetSyncTargets(configuration); got[0].Name != "r/dogs" {
+		t.Errorf("expected home page to be skipped, got %v", got[0])
+	}
+}
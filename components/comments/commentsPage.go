@@ -1,25 +1,51 @@
 package comments
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"reddittui/client"
+	"reddittui/client/auth"
+	"reddittui/client/common"
 	"reddittui/components/messages"
 	"reddittui/components/styles"
 	"reddittui/model"
+	"reddittui/utils"
+	"regexp"
+	"slices"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-var commentsErrorText = "Could not load comments. Please try again in a few moments."
+var subredditRegex = regexp.MustCompile("/r/([^/?]+)")
+
+var (
+	commentsErrorText       = "Could not load comments. Please try again in a few moments."
+	moreCommentsErrorText   = "Could not load more comments. Please try again in a few moments."
+	moreCommentsOfflineText = "More comments are not available offline"
+	over18Text              = "%s is marked as over 18. Are you over 18 and want to continue?"
+	cannotVoteText          = "Only comments can be voted on or saved"
+	cannotReplyDeletedText  = "Deleted and removed comments cannot be replied to"
+	draftErrorText          = "Could not open the editor"
+	confirmReplyText        = "Post your reply to %s?"
+	draftKeptText           = "%s Your draft was kept and opens again the next time you reply."
+)
 
 type CommentsPage struct {
 	redditClient   client.RedditClient
 	header         CommentsHeader
 	pager          CommentsViewport
 	containerStyle lipgloss.Style
+	comments       model.Comments
 	postUrl        string
+	url            string
+	loadingUrl     string
+	sort           string
 	focus          bool
+	cancel         context.CancelFunc
 }
 
 func NewCommentsPage(redditClient client.RedditClient) CommentsPage {
@@ -56,11 +82,47 @@ func (c CommentsPage) Update(msg tea.Msg) (CommentsPage, tea.Cmd) {
 func (c CommentsPage) handleGlobalMessages(msg tea.Msg) (CommentsPage, tea.Cmd) {
 	switch msg := msg.(type) {
 	case messages.LoadCommentsMsg:
-		url := string(msg)
-		return c, c.loadComments(url)
+		return c, c.loadComments(string(msg), "")
+	case messages.SortCommentsMsg:
+		return c, c.loadComments(c.url, string(msg))
 	case messages.UpdateCommentsMsg:
-		c.updateComments(model.Comments(msg))
+		comments := model.Comments(msg)
+		c.cancelLoading()
+		c.url = c.loadingUrl
+		c.updateComments(comments)
+
+		if comments.Stale {
+			return c, tea.Batch(messages.LoadingComplete, c.revalidateComments(c.url, comments.Sort))
+		}
+
 		return c, messages.LoadingComplete
+	case messages.RefreshCommentsMsg:
+		comments := model.Comments(msg)
+		if c.comments.Stale && isSamePost(c.comments, comments) {
+			c.refreshComments(comments)
+		}
+	case messages.LoadMoreCommentsMsg:
+		return c, c.loadMoreComments(msg.Index, msg.Stub)
+	case messages.CancelLoadingMsg:
+		c.cancelLoading()
+	case messages.AddMoreCommentsMsg:
+		c.cancelLoading()
+		return c, tea.Batch(messages.LoadingComplete, c.addMoreComments(msg.Index, msg.Stub, msg.Comments))
+	case messages.AddReplyMsg:
+		// Replies to the post are only added while it is still shown
+		post := model.Post{Id: c.comments.PostId}
+		if msg.Parent != post.Fullname() && !strings.HasPrefix(msg.Parent, "t1_") {
+			return c, nil
+		}
+
+		if c.pager.AddReply(msg.Parent, msg.Reply) {
+			c.comments.Comments = c.pager.Comments()
+			c.header.SetContent(c.comments)
+		}
+	case messages.RollbackCommentMsg:
+		if c.pager.UpdateComment(model.Comment(msg)) {
+			c.comments.Comments = c.pager.Comments()
+		}
 	}
 
 	return c, nil
@@ -76,8 +138,43 @@ func (c CommentsPage) handleFocusedMessages(msg tea.Msg) (CommentsPage, tea.Cmd)
 		case "escape", "backspace", "left", "h":
 			return c, messages.GoBack
 
-		case "o", "O":
+		case "o":
 			return c, messages.OpenUrl(c.postUrl)
+
+		case "O":
+			return c, messages.ShowCommentSortModal(c.sort)
+
+		case "U":
+			author := c.comments.PostAuthor
+			if _, comment, ok := c.pager.FocusedComment(); ok && !comment.IsMore() {
+				author = comment.Author
+			}
+
+			if !model.IsDeletedAuthor(author) {
+				return c, messages.LoadUser(author)
+			}
+
+			return c, nil
+
+		case "enter":
+			if i, comment, ok := c.pager.FocusedComment(); ok && comment.IsMore() {
+				return c, messages.LoadMoreComments(i, comment)
+			}
+
+		case "a":
+			return c, c.vote(model.Upvote)
+
+		case "z":
+			return c, c.vote(model.Downvote)
+
+		case "S":
+			return c, c.toggleSave()
+
+		case "x":
+			return c, c.hidePost()
+
+		case "r":
+			return c, c.reply()
 		}
 	}
 
@@ -118,23 +215,297 @@ func (c *CommentsPage) resizeComponents() {
 	c.pager.SetSize(w, pagerHeight)
 }
 
-func (c *CommentsPage) loadComments(url string) tea.Cmd {
+// Cancel the request still in flight, if any, and return a context for the next one
+func (c *CommentsPage) startLoading() context.Context {
+	c.cancelLoading()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	return ctx
+}
+
+func (c *CommentsPage) cancelLoading() {
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
+}
+
+// The page keeps showing the current post until the new one loads, so only remember the url being loaded
+func (c *CommentsPage) loadComments(url, sort string) tea.Cmd {
+	c.loadingUrl = url
+	ctx := c.startLoading()
 	return func() tea.Msg {
-		comments, err := c.redditClient.GetComments(url)
-		if err != nil {
+		comments, err := c.redditClient.GetComments(ctx, url, sort)
+		if ctx.Err() != nil {
+			// Loading was cancelled, the user has already gone back to the previous page
+			return nil
+		} else if errors.Is(err, common.ErrOver18) {
+			return c.confirmOver18(url, sort)
+		} else if err != nil {
 			slog.Error(commentsErrorText, "error", err)
-			return messages.ShowErrorModalMsg{ErrorMsg: commentsErrorText}
+			return messages.ShowErrorModalMsg{ErrorMsg: messages.DescribeError(err, postSubject(url), commentsErrorText)}
 		}
 
 		return messages.UpdateCommentsMsg(comments)
 	}
 }
 
+// Ask the user to confirm they are over 18, then load the comments again
+func (c CommentsPage) confirmOver18(url, sort string) tea.Msg {
+	var retry tea.Msg = messages.LoadCommentsMsg(url)
+	if url == c.url {
+		retry = messages.SortCommentsMsg(sort)
+	}
+
+	onConfirm := func() tea.Msg {
+		c.redditClient.ConfirmOver18()
+		return retry
+	}
+
+	return messages.ShowConfirmModalMsg{Message: fmt.Sprintf(over18Text, postSubject(url)), OnConfirm: onConfirm}
+}
+
+func (c *CommentsPage) loadMoreComments(index int, stub model.Comment) tea.Cmd {
+	postId := c.comments.PostId
+	ctx := c.startLoading()
+	return func() tea.Msg {
+		replies, err := c.redditClient.GetMoreComments(ctx, postId, stub, c.sort)
+		if ctx.Err() != nil {
+			return nil
+		} else if errors.Is(err, common.ErrOffline) {
+			return messages.ShowErrorModalMsg{ErrorMsg: moreCommentsOfflineText}
+		} else if err != nil {
+			slog.Error(moreCommentsErrorText, "error", err)
+			return messages.ShowErrorModalMsg{ErrorMsg: messages.DescribeError(err, postSubject(c.url), moreCommentsErrorText)}
+		}
+
+		return messages.AddMoreCommentsMsg{Index: index, Stub: stub, Comments: replies}
+	}
+}
+
+// Splice the loaded replies in place of the placeholder and cache them with the rest of the thread
+func (c *CommentsPage) addMoreComments(index int, stub model.Comment, replies []model.Comment) tea.Cmd {
+	if !c.pager.ReplaceComment(index, stub, replies) {
+		return nil
+	}
+
+	c.comments.Comments = c.pager.Comments()
+	c.header.SetContent(c.comments)
+
+	// The cache is written from a copy since the page's comments may change while it is written
+	url, comments := c.url, c.comments
+	comments.Comments = slices.Clone(comments.Comments)
+	return func() tea.Msg {
+		if err := c.redditClient.CacheComments(context.Background(), url, comments); err != nil {
+			slog.Warn("Could not cache loaded replies", "error", err)
+		}
+
+		return nil
+	}
+}
+
+// Vote on the focused comment right away, rolling back if reddit rejects the vote
+func (c *CommentsPage) vote(vote int) tea.Cmd {
+	comment, cmd := c.focusedAction("vote")
+	if cmd != nil {
+		return cmd
+	}
+
+	updated := comment.WithVote(model.ToggleVote(comment.Vote, vote))
+	c.updateComment(updated)
+
+	return func() tea.Msg {
+		if err := c.redditClient.Vote(context.Background(), comment.Fullname(), updated.Vote); err != nil {
+			slog.Error("Could not vote", "comment", comment.Fullname(), "error", err)
+			return messages.RollbackComment(comment, messages.DescribeActionError(err, "vote"))
+		}
+
+		return nil
+	}
+}
+
+func (c *CommentsPage) toggleSave() tea.Cmd {
+	comment, cmd := c.focusedAction("save")
+	if cmd != nil {
+		return cmd
+	}
+
+	updated := comment
+	updated.Saved = !comment.Saved
+	c.updateComment(updated)
+
+	return func() tea.Msg {
+		if err := c.redditClient.Save(context.Background(), comment.Fullname(), updated.Saved); err != nil {
+			slog.Error("Could not save", "comment", comment.Fullname(), "error", err)
+			return messages.RollbackComment(comment, messages.DescribeActionError(err, "save"))
+		}
+
+		return nil
+	}
+}
+
+// Hide the post being read and go back to the posts, which no longer list it
+func (c *CommentsPage) hidePost() tea.Cmd {
+	if !c.redditClient.IsLoggedIn() {
+		return messages.ShowErrorModal(messages.DescribeActionError(auth.ErrNotLoggedIn, "hide posts"))
+	} else if len(c.comments.PostId) == 0 {
+		return nil
+	}
+
+	fullname := model.Post{Id: c.comments.PostId}.Fullname()
+	hide := func() tea.Msg {
+		if err := c.redditClient.Hide(context.Background(), fullname, true); err != nil {
+			slog.Error("Could not hide", "post", fullname, "error", err)
+			return messages.RollbackHide(fullname, messages.DescribeActionError(err, "hide posts"))
+		}
+
+		return nil
+	}
+
+	return tea.Batch(messages.HidePost(fullname), messages.GoBack, hide)
+}
+
+// Open the user's editor to reply to the focused comment, or to the post when no comment is focused.
+// The reply is posted after the editor exits and the user confirms
+func (c *CommentsPage) reply() tea.Cmd {
+	if !c.redditClient.IsLoggedIn() {
+		return messages.ShowErrorModal(messages.DescribeActionError(auth.ErrNotLoggedIn, "reply"))
+	}
+
+	parent := model.Post{Id: c.comments.PostId}.Fullname()
+	author, text := c.comments.PostAuthor, c.comments.PostTitle
+	if len(c.comments.PostText) > 0 {
+		text = c.comments.PostText
+	}
+
+	if _, comment, ok := c.pager.FocusedComment(); ok && comment.Deleted {
+		return messages.ShowErrorModal(cannotReplyDeletedText)
+	} else if ok && len(comment.Fullname()) > 0 {
+		parent, author, text = comment.Fullname(), comment.Author, comment.Text
+	} else if len(parent) == 0 {
+		return nil
+	}
+
+	path, err := utils.GetDraftPath("reply-" + parent)
+	if err == nil {
+		err = utils.PrepareDraft(path, utils.ReplyTemplate(author, text))
+	}
+	if err != nil {
+		slog.Error(draftErrorText, "error", err)
+		return messages.ShowErrorModal(draftErrorText)
+	}
+
+	return tea.ExecProcess(utils.EditorCommand(path), func(err error) tea.Msg {
+		if err != nil {
+			slog.Error(draftErrorText, "error", err)
+			return messages.ShowErrorModalMsg{ErrorMsg: draftErrorText}
+		}
+
+		draft, err := utils.ReadDraft(path)
+		if err != nil {
+			slog.Error(draftErrorText, "error", err)
+			return messages.ShowErrorModalMsg{ErrorMsg: draftErrorText}
+		}
+
+		reply := utils.ParseReply(draft)
+		if len(reply) == 0 {
+			// Nothing was written, the reply was cancelled
+			utils.DiscardDraft(path)
+			return nil
+		}
+
+		return messages.ShowConfirmModalMsg{
+			Message:   fmt.Sprintf(confirmReplyText, utils.NormalizeUser(author)),
+			OnConfirm: c.postReply(parent, reply, path),
+		}
+	})
+}
+
+func (c CommentsPage) postReply(parent, reply, draftPath string) tea.Cmd {
+	return func() tea.Msg {
+		comment, err := c.redditClient.Reply(context.Background(), parent, reply)
+		if err != nil {
+			slog.Error("Could not post reply", "parent", parent, "error", err)
+			return messages.ShowErrorModalMsg{ErrorMsg: fmt.Sprintf(draftKeptText, messages.DescribeActionError(err, "reply"))}
+		}
+
+		if err := utils.DiscardDraft(draftPath); err != nil {
+			slog.Warn("Could not remove draft", "path", draftPath, "error", err)
+		}
+
+		return messages.AddReplyMsg{Parent: parent, Reply: comment}
+	}
+}
+
+// Comment that voting and saving apply to, or a command explaining why there is none
+func (c CommentsPage) focusedAction(action string) (model.Comment, tea.Cmd) {
+	if !c.redditClient.IsLoggedIn() {
+		return model.Comment{}, messages.ShowErrorModal(messages.DescribeActionError(auth.ErrNotLoggedIn, action))
+	}
+
+	_, comment, ok := c.pager.FocusedComment()
+	if !ok || len(comment.Fullname()) == 0 {
+		return model.Comment{}, messages.ShowErrorModal(cannotVoteText)
+	}
+
+	return comment, nil
+}
+
+func (c *CommentsPage) updateComment(comment model.Comment) {
+	if c.pager.UpdateComment(comment) {
+		c.comments.Comments = c.pager.Comments()
+	}
+}
+
+// Refresh stale comments in the background. The page keeps showing the stale comments if this fails
+func (c CommentsPage) revalidateComments(url, sort string) tea.Cmd {
+	return func() tea.Msg {
+		comments, err := c.redditClient.RevalidateComments(context.Background(), url, sort)
+		if err != nil {
+			slog.Warn("Could not refresh stale comments", "error", err)
+			return nil
+		}
+
+		return messages.RefreshCommentsMsg(comments)
+	}
+}
+
+// Swap refreshed comments in for stale ones without moving the scroll position
+func (c *CommentsPage) refreshComments(comments model.Comments) {
+	c.comments = comments
+	c.header.SetContent(comments)
+	c.header.SetInstance(c.redditClient.ActiveInstance())
+	c.header.SetOffline(c.redditClient.IsOffline())
+	c.pager.RefreshContent(comments)
+	c.postUrl = comments.PostUrl
+
+	c.resizeComponents()
+}
+
+// Refreshed comments only replace stale ones when the page is still showing the same post and sort
+func isSamePost(a, b model.Comments) bool {
+	return a.PostId == b.PostId && a.PostTitle == b.PostTitle && a.Sort == b.Sort
+}
+
 func (c *CommentsPage) updateComments(comments model.Comments) {
+	c.comments = comments
 	c.header.SetContent(comments)
+	c.header.SetInstance(c.redditClient.ActiveInstance())
+	c.header.SetOffline(c.redditClient.IsOffline())
 	c.pager.SetContent(comments)
 	c.postUrl = comments.PostUrl
+	c.sort = comments.Sort
 
 	// Need to resize components when content loads so padding and margins are correct
 	c.resizeComponents()
 }
+
+// Posts are private, quarantined or banned along with their subreddit, so describe errors using the subreddit
+func postSubject(url string) string {
+	if matches := subredditRegex.FindStringSubmatch(url); len(matches) == 2 {
+		return utils.NormalizeSubreddit(matches[1])
+	}
+
+	return "This post"
+}
@@ -6,6 +6,7 @@ import (
 	"reddittui/model"
 	"reddittui/utils"
 	"strconv"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -31,6 +32,10 @@ type CommentsHeader struct {
 	Author           string
 	Timestamp        string
 	Points           string
+	Sort             string
+	Instance         string
+	Cached           string
+	Offline          bool
 	TotalComments    int
 	W                int
 }
@@ -54,7 +59,19 @@ func (h CommentsHeader) View() string {
 
 	postPointsView := postPointsStyle.Render(utils.GetSingularPlural(h.Points, "point", "points"))
 	totalCommentsView := totalCommentsStyle.Render(utils.GetSingularPlural(strconv.Itoa(h.TotalComments), "comment", "comments"))
-	pointsAndCommentsView := fmt.Sprintf("%s • %s", postPointsView, totalCommentsView)
+	sortView := commentSortStyle.Render(fmt.Sprintf("sorted by %s", model.CommentSortDescription(h.Sort)))
+	pointsAndCommentsView := fmt.Sprintf("%s • %s • %s", postPointsView, totalCommentsView, sortView)
+	if h.Offline {
+		offlineView := commentSortStyle.Render("offline")
+		pointsAndCommentsView = fmt.Sprintf("%s • %s", pointsAndCommentsView, offlineView)
+	} else if len(h.Instance) > 0 {
+		instanceView := commentSortStyle.Render(fmt.Sprintf("via %s", h.Instance))
+		pointsAndCommentsView = fmt.Sprintf("%s • %s", pointsAndCommentsView, instanceView)
+	}
+	if len(h.Cached) > 0 {
+		cachedView := commentSortStyle.Render(h.Cached)
+		pointsAndCommentsView = fmt.Sprintf("%s • %s", pointsAndCommentsView, cachedView)
+	}
 
 	joinedView := lipgloss.JoinVertical(lipgloss.Left, titleView, descriptionView, authorTimestampView, pointsAndCommentsView)
 
@@ -65,7 +82,18 @@ func (h *CommentsHeader) SetContent(comments model.Comments) {
 	h.Title = utils.NormalizeSubreddit(comments.Subreddit)
 	h.Description = comments.PostTitle
 	h.Author = comments.PostAuthor
-	h.TotalComments = len(comments.Comments)
+	h.TotalComments = comments.Count()
 	h.Timestamp = comments.PostTimestamp
 	h.Points = comments.PostPoints
+	h.Sort = comments.Sort
+	h.Cached = utils.CachedDescription(comments.Stale, comments.Fetched, time.Now())
+}
+
+func (h *CommentsHeader) SetOffline(offline bool) {
+	h.Offline = offline
+}
+
+// Show which server the comments were loaded from when failing over between several
+func (h *CommentsHeader) SetInstance(instance string) {
+	h.Instance = instance
 }
@@ -3,17 +3,31 @@ package comments
 import "github.com/charmbracelet/bubbles/key"
 
 type viewportKeyMap struct {
-	CursorUp         key.Binding
-	CursorDown       key.Binding
-	GoToStart        key.Binding
-	GoToEnd          key.Binding
-	OpenPost         key.Binding
-	GoHome           key.Binding
-	CollapseComments key.Binding
-	ShowFullHelp     key.Binding
-	CloseFullHelp    key.Binding
-	Quit             key.Binding
-	ForceQuit        key.Binding
+	CursorUp          key.Binding
+	CursorDown        key.Binding
+	GoToStart         key.Binding
+	GoToEnd           key.Binding
+	NextComment       key.Binding
+	PrevComment       key.Binding
+	ScrollLeft        key.Binding
+	ScrollRight       key.Binding
+	RevealSpoilers    key.Binding
+	RevealAllSpoilers key.Binding
+	LoadMore          key.Binding
+	ViewAuthor        key.Binding
+	OpenPost          key.Binding
+	SortComments      key.Binding
+	GoHome            key.Binding
+	CollapseComments  key.Binding
+	Upvote            key.Binding
+	Downvote          key.Binding
+	Save              key.Binding
+	HidePost          key.Binding
+	Reply             key.Binding
+	ShowFullHelp      key.Binding
+	CloseFullHelp     key.Binding
+	Quit              key.Binding
+	ForceQuit         key.Binding
 }
 
 var commentsKeys = viewportKeyMap{
@@ -33,10 +47,46 @@ var commentsKeys = viewportKeyMap{
 		key.WithKeys("end", "G"),
 		key.WithHelp("G/end", "go to end"),
 	),
+	NextComment: key.NewBinding(
+		key.WithKeys("J"),
+		key.WithHelp("J", "next comment"),
+	),
+	PrevComment: key.NewBinding(
+		key.WithKeys("K"),
+		key.WithHelp("K", "previous comment"),
+	),
+	ScrollLeft: key.NewBinding(
+		key.WithKeys("<", "shift+left"),
+		key.WithHelp("</shift+←", "scroll code left"),
+	),
+	ScrollRight: key.NewBinding(
+		key.WithKeys(">", "shift+right"),
+		key.WithHelp(">/shift+→", "scroll code right"),
+	),
+	RevealSpoilers: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "reveal spoilers"),
+	),
+	RevealAllSpoilers: key.NewBinding(
+		key.WithKeys("V"),
+		key.WithHelp("V", "reveal all spoilers"),
+	),
+	LoadMore: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "load more comments"),
+	),
+	ViewAuthor: key.NewBinding(
+		key.WithKeys("U"),
+		key.WithHelp("U", "view author"),
+	),
 	OpenPost: key.NewBinding(
-		key.WithKeys("o", "O"),
+		key.WithKeys("o"),
 		key.WithHelp("o", "open post"),
 	),
+	SortComments: key.NewBinding(
+		key.WithKeys("O"),
+		key.WithHelp("O", "sort comments"),
+	),
 	GoHome: key.NewBinding(
 		key.WithKeys("H"),
 		key.WithHelp("H", "go home"),
@@ -45,6 +95,26 @@ var commentsKeys = viewportKeyMap{
 		key.WithKeys("c"),
 		key.WithHelp("c", "collapse comments"),
 	),
+	Upvote: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "upvote"),
+	),
+	Downvote: key.NewBinding(
+		key.WithKeys("z"),
+		key.WithHelp("z", "downvote"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "save"),
+	),
+	HidePost: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "hide post"),
+	),
+	Reply: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "reply"),
+	),
 	ShowFullHelp: key.NewBinding(
 		key.WithKeys("?"),
 		key.WithHelp("?", "more"),
@@ -66,7 +136,10 @@ func (k viewportKeyMap) ShortHelp() []key.Binding {
 
 func (k viewportKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.CursorUp, k.CursorDown, k.GoToStart, k.GoToEnd, k.OpenPost},
-		{k.GoHome, k.CollapseComments, k.Quit, k.CloseFullHelp},
+		{k.CursorUp, k.CursorDown, k.GoToStart, k.GoToEnd, k.NextComment, k.PrevComment},
+		{k.ScrollLeft, k.ScrollRight, k.RevealSpoilers, k.RevealAllSpoilers},
+		{k.OpenPost, k.LoadMore, k.ViewAuthor},
+		{k.Upvote, k.Downvote, k.Save, k.HidePost, k.Reply},
+		{k.GoHome, k.CollapseComments, k.SortComments, k.Quit, k.CloseFullHelp},
 	}
 }
@@ -2,7 +2,9 @@ package comments
 
 import (
 	"fmt"
+	"reddittui/client/render"
 	"reddittui/model"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -11,18 +13,57 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/ansi"
+)
+
+const (
+	// Width reserved on the left of each line for the focused comment marker
+	gutterWidth = 2
+
+	// Deeply nested comments are not squeezed narrower than this
+	minTextWidth = 20
+
+	// Columns moved by each sideways scroll
+	horizontalStep = 8
+
+	// Key the post's spoilers are revealed under, comments use their fullname
+	postSpoilersKey = "post"
 )
 
 type CommentsViewport struct {
-	viewport      viewport.Model
-	postText      string
-	postUrl       string
-	comments      []model.Comment
-	keyMap        viewportKeyMap
-	help          help.Model
-	collapsed     bool
-	viewportLines []string
-	w, h          int
+	viewport       viewport.Model
+	postText       string
+	postHtml       string
+	postUrl        string
+	comments       []model.Comment
+	keyMap         viewportKeyMap
+	help           help.Model
+	collapsed      bool
+	viewportLines  []string
+	commentLines   []int
+	commentHeights []int
+	focus          int
+	w, h           int
+
+	// Lines wider than the viewport, such as code blocks, are scrolled sideways together by xOffset,
+	// up to the overflow of the widest line
+	xOffset, overflow int
+
+	// Markdown already rendered at the current width, rendering every comment again on each
+	// change to the viewport is slow for large threads
+	rendered map[renderKey]string
+
+	// Spoilers are hidden until revealed in the post or a comment, keyed by fullname, or in the
+	// whole thread
+	revealed  map[string]bool
+	revealAll bool
+}
+
+type renderKey struct {
+	source string
+	width  int
+	reveal bool
 }
 
 func NewCommentsViewport() CommentsViewport {
@@ -31,6 +72,7 @@ func NewCommentsViewport() CommentsViewport {
 		keyMap:    commentsKeys,
 		help:      help.New(),
 		collapsed: false,
+		focus:     -1,
 	}
 }
 
@@ -44,6 +86,24 @@ func (c CommentsViewport) Update(msg tea.Msg) (CommentsViewport, tea.Cmd) {
 			c.viewport.GotoBottom()
 		case key.Matches(msg, c.keyMap.CollapseComments):
 			c.toggleCollapseComments()
+		case key.Matches(msg, c.keyMap.NextComment):
+			c.moveFocus(1)
+			return c, nil
+		case key.Matches(msg, c.keyMap.PrevComment):
+			c.moveFocus(-1)
+			return c, nil
+		case key.Matches(msg, c.keyMap.ScrollLeft):
+			c.scrollHorizontally(-horizontalStep)
+			return c, nil
+		case key.Matches(msg, c.keyMap.ScrollRight):
+			c.scrollHorizontally(horizontalStep)
+			return c, nil
+		case key.Matches(msg, c.keyMap.RevealSpoilers):
+			c.toggleSpoilers()
+			return c, nil
+		case key.Matches(msg, c.keyMap.RevealAllSpoilers):
+			c.toggleAllSpoilers()
+			return c, nil
 		case key.Matches(msg, c.keyMap.ShowFullHelp),
 			key.Matches(msg, c.keyMap.CloseFullHelp):
 			c.help.ShowAll = !c.help.ShowAll
@@ -52,17 +112,44 @@ func (c CommentsViewport) Update(msg tea.Msg) (CommentsViewport, tea.Cmd) {
 
 	var cmd tea.Cmd
 	c.viewport, cmd = c.viewport.Update(msg)
+	c.clampFocus()
 	return c, cmd
 }
 
 func (c CommentsViewport) View() string {
-	viewportView := viewportStyle.Render(c.viewport.View())
+	viewportView := viewportStyle.Render(c.renderGutter(c.viewport.View()))
 	helpView := c.help.View(c.keyMap)
 	return lipgloss.JoinVertical(lipgloss.Left, viewportView, helpView)
 }
 
+// Mark the lines of the focused comment in the gutter to the left of the comments
+func (c CommentsViewport) renderGutter(view string) string {
+	var (
+		lines       = strings.Split(view, "\n")
+		emptyGutter = strings.Repeat(" ", gutterWidth)
+		focusGutter = focusMarkerStyle.Render("▎") + strings.Repeat(" ", gutterWidth-1)
+		start, end  = -1, -1
+	)
+
+	if c.focus >= 0 && c.focus < len(c.commentLines) {
+		start = c.commentLines[c.focus]
+		end = start + c.commentHeights[c.focus]
+	}
+
+	for i, line := range lines {
+		lineNumber := c.viewport.YOffset + i
+		if start >= 0 && lineNumber >= start && lineNumber < end {
+			lines[i] = focusGutter + line
+		} else {
+			lines[i] = emptyGutter + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 func (c *CommentsViewport) SetSize(w, h int) {
-	c.w = w - viewportStyle.GetHorizontalFrameSize()
+	c.w = w - viewportStyle.GetHorizontalFrameSize() - gutterWidth
 	c.h = h
 
 	c.ResizeComponents()
@@ -71,13 +158,121 @@ func (c *CommentsViewport) SetSize(w, h int) {
 
 func (c *CommentsViewport) SetContent(comments model.Comments) {
 	c.postText = comments.PostText
+	c.postHtml = comments.PostHtml
 	c.postUrl = comments.PostUrl
 	c.comments = comments.Comments
+	c.rendered = nil
+	c.revealed = nil
+	c.revealAll = false
 
+	c.xOffset = 0
 	c.collapsed = false
+	c.focus = -1
 	c.viewport.SetYOffset(0)
 	c.ResizeComponents()
 	c.SetViewportContent()
+	c.clampFocus()
+}
+
+// Replace the comments while keeping the scroll position and collapsed state. The focused comment
+// stays at the same place on screen when it is still in the thread
+func (c *CommentsViewport) RefreshContent(comments model.Comments) {
+	focusedId, focusOffset := "", 0
+	if _, comment, ok := c.FocusedComment(); ok && c.isVisible(c.focus) {
+		focusedId = comment.Id
+		focusOffset = c.commentLines[c.focus] - c.viewport.YOffset
+	}
+
+	c.postText = comments.PostText
+	c.postHtml = comments.PostHtml
+	c.postUrl = comments.PostUrl
+	c.comments = comments.Comments
+	c.rendered = nil
+	c.focus = -1
+
+	yOffset := c.viewport.YOffset
+	c.ResizeComponents()
+	c.SetViewportContent()
+
+	if len(focusedId) > 0 {
+		i := slices.IndexFunc(c.comments, func(comment model.Comment) bool { return comment.Id == focusedId })
+		if i >= 0 && c.commentLines[i] >= 0 {
+			c.focus = i
+			yOffset = c.commentLines[i] - focusOffset
+		}
+	}
+
+	c.viewport.SetYOffset(yOffset)
+	c.clampFocus()
+}
+
+// Get the comment that actions such as loading more replies apply to
+func (c CommentsViewport) FocusedComment() (int, model.Comment, bool) {
+	if c.focus < 0 || c.focus >= len(c.comments) {
+		return -1, model.Comment{}, false
+	}
+
+	return c.focus, c.comments[c.focus], true
+}
+
+// Replace the placeholder at index with the replies it was hiding, keeping the scroll position
+func (c *CommentsViewport) ReplaceComment(index int, stub model.Comment, replies []model.Comment) bool {
+	if index < 0 || index >= len(c.comments) {
+		return false
+	}
+
+	current := c.comments[index]
+	if current.Depth != stub.Depth || current.MoreUrl != stub.MoreUrl || !slices.Equal(current.MoreIds, stub.MoreIds) {
+		// Comments were reloaded while fetching the replies
+		return false
+	}
+
+	c.comments = slices.Concat(c.comments[:index], replies, c.comments[index+1:])
+	c.SetViewportContent()
+	c.clampFocus()
+	return true
+}
+
+// Replace the comment with the same id after voting or saving, keeping the scroll position
+func (c *CommentsViewport) UpdateComment(comment model.Comment) bool {
+	i := slices.IndexFunc(c.comments, func(current model.Comment) bool {
+		return current.Fullname() == comment.Fullname()
+	})
+	if i < 0 || len(comment.Fullname()) == 0 {
+		return false
+	}
+
+	c.comments[i] = comment
+	c.SetViewportContent()
+	return true
+}
+
+// Show a new reply right below the comment it answers, or at the top of the thread for replies to the
+// post, and focus it
+func (c *CommentsViewport) AddReply(parent string, reply model.Comment) bool {
+	index := 0
+	if strings.HasPrefix(parent, "t1_") {
+		i := slices.IndexFunc(c.comments, func(comment model.Comment) bool { return comment.Fullname() == parent })
+		if i < 0 {
+			return false
+		}
+
+		index = i + 1
+		reply.Depth = c.comments[i].Depth + 1
+	}
+
+	c.comments = slices.Insert(c.comments, index, reply)
+	c.SetViewportContent()
+	if c.commentLines[index] >= 0 {
+		c.focus = index - 1
+		c.moveFocus(1)
+	}
+
+	return true
+}
+
+func (c *CommentsViewport) Comments() []model.Comment {
+	return c.comments
 }
 
 func (c *CommentsViewport) ResizeComponents() {
@@ -90,7 +285,10 @@ func (c *CommentsViewport) ResizeComponents() {
 func (c *CommentsViewport) GetViewportView() string {
 	var content strings.Builder
 
-	if len(c.postText) > 0 {
+	if len(c.postHtml) > 0 {
+		content.WriteString(c.renderMarkdown(c.postHtml, c.w, c.isRevealed(postSpoilersKey)))
+		content.WriteString("\n\n\n")
+	} else if len(c.postText) > 0 {
 		content.WriteString(c.postText)
 		content.WriteString("\n")
 	} else {
@@ -98,12 +296,23 @@ func (c *CommentsViewport) GetViewportView() string {
 		content.WriteString("\n\n")
 	}
 
+	c.commentLines = make([]int, len(c.comments))
+	c.commentHeights = make([]int, len(c.comments))
+	line := strings.Count(content.String(), "\n")
+
 	for i := range len(c.comments) {
 		comment := c.comments[i]
 		commentView := c.formatComment(comment, i)
 		if len(commentView) > 0 {
+			height := lipgloss.Height(commentView)
+			c.commentLines[i] = line
+			c.commentHeights[i] = height
+			line += height + 1
+
 			content.WriteString(commentView)
 			content.WriteString("\n\n")
+		} else {
+			c.commentLines[i] = -1
 		}
 	}
 
@@ -112,8 +321,42 @@ func (c *CommentsViewport) GetViewportView() string {
 
 func (c *CommentsViewport) SetViewportContent() {
 	content := c.GetViewportView()
-	c.viewport.SetContent(content)
 	c.viewportLines = strings.Split(content, "\n")
+
+	c.overflow = 0
+	for _, line := range c.viewportLines {
+		c.overflow = max(c.overflow, lipgloss.Width(line)-c.viewport.Width)
+	}
+
+	c.xOffset = min(c.xOffset, c.overflow)
+	c.scrollContent()
+}
+
+func (c *CommentsViewport) scrollHorizontally(n int) {
+	xOffset := min(max(c.xOffset+n, 0), c.overflow)
+	if xOffset != c.xOffset {
+		c.xOffset = xOffset
+		c.scrollContent()
+	}
+}
+
+// Show the content with the lines wider than the viewport scrolled sideways. Other lines already fit
+// and stay in place
+func (c *CommentsViewport) scrollContent() {
+	if c.xOffset == 0 {
+		c.viewport.SetContent(strings.Join(c.viewportLines, "\n"))
+		return
+	}
+
+	lines := make([]string, len(c.viewportLines))
+	for i, line := range c.viewportLines {
+		if overflow := lipgloss.Width(line) - c.viewport.Width; overflow > 0 {
+			line = cutLeft(line, min(c.xOffset, overflow))
+		}
+		lines[i] = line
+	}
+
+	c.viewport.SetContent(strings.Join(lines, "\n"))
 }
 
 // Format comment, adding padding to the entry according to the comment's depth
@@ -123,43 +366,139 @@ func (c *CommentsViewport) formatComment(comment model.Comment, i int) string {
 		pointsView                 string
 		pointsAndCollapsedHintView string
 		paddingW                   = comment.Depth * 2
-		containerStyle             = lipgloss.NewStyle().PaddingLeft(paddingW).Width(c.w - paddingW)
+		textW                      = max(c.w-2*paddingW, minTextWidth)
+		containerStyle             = lipgloss.NewStyle().PaddingLeft(paddingW)
 	)
 
 	if c.collapsed && comment.Depth > 0 {
 		return ""
 	}
 
+	if comment.IsMore() {
+		moreView := moreCommentsStyle.Render(fmt.Sprintf("↳ %s", comment.MoreDescription()))
+		return containerStyle.Render(moreView)
+	}
+
+	if comment.Deleted {
+		// Placeholders only hold the place of their replies, so they are dimmed and kept to one line
+		deletedView := deletedCommentStyle.Render(comment.Text)
+		if len(comment.Timestamp) > 0 {
+			deletedView = fmt.Sprintf("%s • %s", deletedView, deletedCommentStyle.Render(comment.Timestamp))
+		}
+
+		if hint := c.collapsedHint(i); c.collapsed && len(hint) > 0 {
+			deletedView = fmt.Sprintf("%s  %s", deletedView, hint)
+		}
+
+		return containerStyle.Render(deletedView)
+	}
+
 	authorView := commentAuthorStyle.Render(comment.Author)
 	dateView := commentDateStyle.Render(comment.Timestamp)
 	authorAndDateView = fmt.Sprintf("%s • %s", authorView, dateView)
-	pointsView = renderPoints(comment.Points)
+	pointsView = renderPoints(comment.Points, comment.Vote)
+	if comment.Saved {
+		pointsView = fmt.Sprintf("%s  %s", pointsView, savedStyle.Render("saved"))
+	}
 	pointsAndCollapsedHintView = pointsView
 
-	if c.collapsed {
-		children := 0
-		for j := i + 1; j < len(c.comments); j++ {
-			nextComment := c.comments[j]
-			if nextComment.Depth == 0 {
-				break
-			}
+	if hint := c.collapsedHint(i); c.collapsed && len(hint) > 0 {
+		pointsAndCollapsedHintView = fmt.Sprintf("%s  %s", pointsView, hint)
+	}
+
+	// The text is wrapped here rather than by the container so code blocks can be wider than the
+	// viewport and scrolled sideways
+	text := lipgloss.NewStyle().Width(textW).Render(comment.Text)
+	if len(comment.Html) > 0 {
+		text = c.renderMarkdown(comment.Html, textW, c.isRevealed(comment.Fullname()))
+	}
+
+	joined := lipgloss.JoinVertical(lipgloss.Left, authorAndDateView, text, pointsAndCollapsedHintView)
+	return containerStyle.Render(joined)
+}
+
+// Render the markdown html of the post or a comment to fit the width. Replies posted from reddittui
+// have no html and are shown with the text the api returned instead
+func (c *CommentsViewport) renderMarkdown(source string, width int, reveal bool) string {
+	key := renderKey{source, width, reveal}
+	if text, ok := c.rendered[key]; ok {
+		return text
+	}
+
+	if c.rendered == nil {
+		c.rendered = make(map[renderKey]string)
+	}
+
+	text := render.Renderer{Width: width, RevealSpoilers: reveal}.RenderHtml(source)
+	c.rendered[key] = text
+	return text
+}
+
+// Reveal the spoilers in the focused comment, or in the post when no comment is focused, or hide
+// them again
+func (c *CommentsViewport) toggleSpoilers() {
+	name := postSpoilersKey
+	if _, comment, ok := c.FocusedComment(); ok {
+		name = comment.Fullname()
+	}
+
+	if len(name) == 0 {
+		return
+	}
+
+	if c.revealed == nil {
+		c.revealed = make(map[string]bool)
+	}
+
+	c.revealed[name] = !c.isRevealed(name)
+	c.SetViewportContent()
+}
+
+// Reveal the spoilers in the whole thread, or hide all of them again
+func (c *CommentsViewport) toggleAllSpoilers() {
+	c.revealAll = !c.revealAll
+	c.revealed = nil
+	c.SetViewportContent()
+}
+
+func (c *CommentsViewport) isRevealed(name string) bool {
+	if revealed, ok := c.revealed[name]; ok {
+		return revealed
+	}
+
+	return c.revealAll
+}
+
+// Number of replies hidden under the top level comment at i while comments are collapsed
+func (c *CommentsViewport) collapsedHint(i int) string {
+	children := 0
+	for j := i + 1; j < len(c.comments); j++ {
+		nextComment := c.comments[j]
+		if nextComment.Depth == 0 {
+			break
+		} else if !nextComment.IsMore() {
 			children++
 		}
+	}
 
-		if children == 1 {
-			collapsedHintView := collapsedStyle.Render("(1 comment hidden)")
-			pointsAndCollapsedHintView = fmt.Sprintf("%s  %s", pointsView, collapsedHintView)
-		} else if children > 1 {
-			collapsedView := collapsedStyle.Render(fmt.Sprintf("(%d comments hidden)", children))
-			pointsAndCollapsedHintView = fmt.Sprintf("%s  %s", pointsView, collapsedView)
-		}
+	if children == 1 {
+		return collapsedStyle.Render("(1 comment hidden)")
+	} else if children > 1 {
+		return collapsedStyle.Render(fmt.Sprintf("(%d comments hidden)", children))
 	}
 
-	joined := lipgloss.JoinVertical(lipgloss.Left, authorAndDateView, comment.Text, pointsAndCollapsedHintView)
-	return containerStyle.Render(joined)
+	return ""
 }
 
-func renderPoints(pointsString string) string {
+// Points are highlighted along with an arrow once the user has voted on the comment
+func renderPoints(pointsString string, vote int) string {
+	switch vote {
+	case model.Upvote:
+		return upvotedPointsStyle.Render("▲ " + pointsString)
+	case model.Downvote:
+		return downvotedPointsStyle.Render("▼ " + pointsString)
+	}
+
 	parts := strings.Fields(pointsString)
 	if len(parts) != 2 {
 		return defaultPointsStyle.Render(pointsString)
@@ -194,6 +533,66 @@ func (c *CommentsViewport) toggleCollapseComments() {
 
 	newPos := c.findComment(title, text)
 	c.viewport.SetYOffset(newPos - offset)
+	c.clampFocus()
+}
+
+func (c *CommentsViewport) isVisible(i int) bool {
+	if i < 0 || i >= len(c.commentLines) || c.commentLines[i] < 0 {
+		return false
+	}
+
+	start, end := c.commentLines[i], c.commentLines[i]+c.commentHeights[i]
+	return end > c.viewport.YOffset && start < c.viewport.YOffset+c.viewport.Height
+}
+
+// Keep the focused comment on screen, moving focus to the first visible comment when scrolling away from it.
+// The post stays focused while it is on screen
+func (c *CommentsViewport) clampFocus() {
+	if c.isVisible(c.focus) || (c.focus < 0 && c.isPostVisible()) {
+		return
+	}
+
+	for i := range c.commentLines {
+		if c.isVisible(i) {
+			c.focus = i
+			return
+		}
+	}
+}
+
+func (c *CommentsViewport) isPostVisible() bool {
+	for _, line := range c.commentLines {
+		if line >= 0 {
+			return c.viewport.YOffset < line
+		}
+	}
+
+	return true
+}
+
+// Move focus to the next or previous displayed comment, scrolling to it if needed. Moving back from the
+// first comment focuses the post again, so that it can be replied to
+func (c *CommentsViewport) moveFocus(direction int) {
+	for i := c.focus + direction; i >= 0 && i < len(c.commentLines); i += direction {
+		if c.commentLines[i] < 0 {
+			continue
+		}
+
+		c.focus = i
+		start, end := c.commentLines[i], c.commentLines[i]+c.commentHeights[i]
+		if start < c.viewport.YOffset {
+			c.viewport.SetYOffset(start)
+		} else if end > c.viewport.YOffset+c.viewport.Height {
+			c.viewport.SetYOffset(min(start, end-c.viewport.Height))
+		}
+
+		return
+	}
+
+	if direction < 0 && c.focus >= 0 {
+		c.focus = -1
+		c.viewport.GotoTop()
+	}
 }
 
 // Find comment closest to the center of the screen to act as an anchor when toggling
@@ -255,3 +654,26 @@ func (c *CommentsViewport) findComment(title, text string) int {
 
 	return -1
 }
+
+// Drop the first n columns of a line, keeping the escape sequences that style the rest of it
+func cutLeft(line string, n int) string {
+	var (
+		sb     strings.Builder
+		column int
+		inAnsi bool
+	)
+
+	for _, r := range line {
+		switch {
+		case r == ansi.Marker || inAnsi:
+			inAnsi = r == ansi.Marker || !ansi.IsTerminator(r)
+			sb.WriteRune(r)
+		case column < n:
+			column += runewidth.RuneWidth(r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
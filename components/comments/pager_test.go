@@ -0,0 +1,80 @@
+package comments
+
+import (
+	"reddittui/model"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func keyPress(key string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+func newTestViewport() CommentsViewport {
+	c := NewCommentsViewport()
+	c.SetSize(80, 40)
+	c.SetContent(model.Comments{
+		PostText: "post text",
+		Comments: []model.Comment{
+			{Id: "first", Author: "alice", Text: "first comment", Points: "1 point", Timestamp: "1h ago"},
+			{Id: "second", Author: "bob", Text: "second comment", Points: "1 point", Timestamp: "1h ago"},
+		},
+	})
+
+	return c
+}
+
+func TestPrevCommentFocusesPost(t *testing.T) {
+	c := newTestViewport()
+	if _, _, ok := c.FocusedComment(); ok {
+		t.Fatalf("expected the post to be focused after loading the comments")
+	}
+
+	c, _ = c.Update(keyPress("J"))
+	if _, comment, ok := c.FocusedComment(); !ok || comment.Id != "first" {
+		t.Fatalf("got focused comment %q, want first", comment.Id)
+	}
+
+	// K on the first comment goes back to the post, which replies then apply to
+	c, _ = c.Update(keyPress("K"))
+	if _, comment, ok := c.FocusedComment(); ok {
+		t.Errorf("got focused comment %q, want the post", comment.Id)
+	}
+
+	// The post keeps focus while it is on screen
+	c, _ = c.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+	if _, comment, ok := c.FocusedComment(); ok {
+		t.Errorf("got focused comment %q after an update, want the post", comment.Id)
+	}
+
+	c, _ = c.Update(keyPress("J"))
+	if _, comment, ok := c.FocusedComment(); !ok || comment.Id != "first" {
+		t.Errorf("got focused comment %q, want first", comment.Id)
+	}
+}
+
+func TestRevealPostSpoilers(t *testing.T) {
+	c := newTestViewport()
+	c.SetContent(model.Comments{
+		PostHtml: `<p>The butler <span class="md-spoiler-text">did it</span>.</p>`,
+		Comments: []model.Comment{
+			{Id: "first", Author: "alice", Text: "first comment", Points: "1 point", Timestamp: "1h ago"},
+		},
+	})
+
+	// v on a comment only reveals that comment's spoilers
+	c, _ = c.Update(keyPress("J"))
+	c, _ = c.Update(keyPress("v"))
+	if view := ansi.Strip(c.GetViewportView()); strings.Contains(view, "did it") {
+		t.Errorf("expected the post spoiler to stay hidden, got %q", view)
+	}
+
+	c, _ = c.Update(keyPress("K"))
+	c, _ = c.Update(keyPress("v"))
+	if view := ansi.Strip(c.GetViewportView()); !strings.Contains(view, "did it") {
+		t.Errorf("expected the post spoiler to be revealed, got %q", view)
+	}
+}
@@ -9,19 +9,26 @@ import (
 var viewportStyle = lipgloss.NewStyle().Margin(0, 2, 1, 2)
 
 var (
-	commentAuthorStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Blue)).Bold(true)
-	commentDateStyle    = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Lavender)).Italic(true)
-	commentTextStyle    = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text))
-	popularPointsStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Purple))
-	defaultPointsStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Purple))
-	negativePointsStyle = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Red))
-	collapsedStyle      = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Yellow))
+	commentAuthorStyle   = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Blue)).Bold(true)
+	commentDateStyle     = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Lavender)).Italic(true)
+	commentTextStyle     = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text))
+	popularPointsStyle   = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Purple))
+	defaultPointsStyle   = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Purple))
+	negativePointsStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Red))
+	upvotedPointsStyle   = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Orange)).Bold(true)
+	downvotedPointsStyle = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Blue)).Bold(true)
+	savedStyle           = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Green))
+	collapsedStyle       = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Yellow))
+	deletedCommentStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text)).Faint(true).Italic(true)
+	moreCommentsStyle    = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Blue)).Italic(true)
+	focusMarkerStyle     = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Blue))
 )
 
 var (
 	postAuthorStyle    = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Blue))
 	postPointsStyle    = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Purple))
 	totalCommentsStyle = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Orange))
+	commentSortStyle   = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Lavender)).Italic(true)
 	postTextStyle      = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Sand))
 	postTimestampStyle = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text)).Faint(true)
 )
@@ -0,0 +1,53 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reddittui/client/auth"
+	"reddittui/client/common"
+)
+
+// Describe an error returned by the reddit client for the error modal. Subject is what was being
+// loaded, i.e. r/dogs, and fallback is shown for errors without a more specific description
+func DescribeError(err error, subject, fallback string) string {
+	var responseErr *common.ResponseError
+
+	switch {
+	case errors.Is(err, common.ErrRateLimited):
+		if errors.As(err, &responseErr) && responseErr.RetryAfter > 0 {
+			seconds := int(math.Ceil(responseErr.RetryAfter.Seconds()))
+			return fmt.Sprintf("Rate limited by the server. Please try again in %d seconds.", seconds)
+		}
+
+		return "Rate limited by the server. Please try again in a few moments."
+	case errors.Is(err, common.ErrForbidden):
+		return fmt.Sprintf("%s is private", subject)
+	case errors.Is(err, common.ErrQuarantined):
+		return fmt.Sprintf("%s is quarantined", subject)
+	case errors.Is(err, common.ErrBanned):
+		return fmt.Sprintf("%s is banned", subject)
+	case errors.Is(err, common.ErrServerError):
+		return "The server is having trouble. Please try again in a few moments."
+	case errors.Is(err, common.ErrOffline):
+		return fmt.Sprintf("%s is not available offline", subject)
+	case errors.Is(err, common.ErrTimeout):
+		return "The server took too long to respond. Please try again in a few moments."
+	default:
+		return fallback
+	}
+}
+
+// Describe why voting, saving or hiding failed. Action is the verb shown to the user, such as "vote"
+func DescribeActionError(err error, action string) string {
+	switch {
+	case errors.Is(err, auth.ErrNotLoggedIn):
+		return fmt.Sprintf("Log in with \"reddittui login\" to %s", action)
+	case errors.Is(err, common.ErrOffline):
+		return fmt.Sprintf("Cannot %s while offline", action)
+	case errors.Is(err, common.ErrForbidden), errors.Is(err, auth.ErrAuthFailed):
+		return fmt.Sprintf("Reddittui is not allowed to %s, run \"reddittui login\" again", action)
+	default:
+		return DescribeError(err, "", fmt.Sprintf("Could not %s. Please try again in a few moments.", action))
+	}
+}
@@ -11,25 +11,79 @@ type ErrorModalMsg struct {
 	OnClose  tea.Cmd
 }
 
+type ConfirmModalMsg struct {
+	Message   string
+	OnConfirm tea.Cmd
+	OnCancel  tea.Cmd
+}
+
+type MoreCommentsMsg struct {
+	Index    int
+	Stub     model.Comment
+	Comments []model.Comment
+}
+
+type ReplyMsg struct {
+	Parent string
+	Reply  model.Comment
+}
+
+type PostSortMsg struct {
+	Kind model.PostsKind
+	Sort model.PostSort
+}
+
+type SearchMsg struct {
+	Query     string
+	Subreddit string
+}
+
 type (
 	CleanCacheMsg      struct{}
 	GoBackMsg          struct{}
 	LoadCommentsMsg    string
 	LoadHomeMsg        struct{}
-	LoadMorePostsMsg   bool
+	LoadMorePostsMsg   model.PostsKind
 	LoadSubredditMsg   string
+	LoadUserMsg        string
+	LoadFeedMsg        model.Feed
 	UpdateCommentsMsg  model.Comments
 	UpdatePostsMsg     model.Posts
 	AddMorePostsMsg    model.Posts
+	RefreshPostsMsg    model.Posts
+	RefreshCommentsMsg model.Comments
 	LoadingCompleteMsg struct{}
+	CancelLoadingMsg   struct{}
 
 	OpenModalMsg        struct{}
 	ExitModalMsg        struct{}
 	ShowSpinnerModalMsg string
 
-	ShowErrorModalMsg ErrorModalMsg
+	ShowErrorModalMsg   ErrorModalMsg
+	ShowConfirmModalMsg ConfirmModalMsg
+
+	ShowSearchModalMsg SearchMsg
+	LoadSearchMsg      SearchMsg
+
+	ShowPostSortModalMsg PostSortMsg
+	SortPostsMsg         PostSortMsg
+
+	ShowCommentSortModalMsg string
+	SortCommentsMsg         string
+
+	LoadMoreCommentsMsg MoreCommentsMsg
+	AddMoreCommentsMsg  MoreCommentsMsg
 
 	OpenUrlMsg string
+
+	UpdateSubscriptionsMsg []string
+
+	HidePostMsg        string
+	UnhidePostMsg      string
+	RollbackPostMsg    model.Post
+	RollbackCommentMsg model.Comment
+
+	AddReplyMsg ReplyMsg
 )
 
 func CleanCache() tea.Msg {
@@ -44,9 +98,9 @@ func LoadHome() tea.Msg {
 	return LoadHomeMsg{}
 }
 
-func LoadMorePosts(home bool) tea.Cmd {
+func LoadMorePosts(kind model.PostsKind) tea.Cmd {
 	return func() tea.Msg {
-		return LoadMorePostsMsg(home)
+		return LoadMorePostsMsg(kind)
 	}
 }
 
@@ -56,6 +110,18 @@ func LoadSubreddit(subreddit string) tea.Cmd {
 	}
 }
 
+func LoadFeed(feed model.Feed) tea.Cmd {
+	return func() tea.Msg {
+		return LoadFeedMsg(feed)
+	}
+}
+
+func LoadUser(user string) tea.Cmd {
+	return func() tea.Msg {
+		return LoadUserMsg(user)
+	}
+}
+
 func LoadComments(url string) tea.Cmd {
 	return func() tea.Msg {
 		return LoadCommentsMsg(url)
@@ -66,6 +132,10 @@ func LoadingComplete() tea.Msg {
 	return LoadingCompleteMsg{}
 }
 
+func CancelLoading() tea.Msg {
+	return CancelLoadingMsg{}
+}
+
 func OpenModal() tea.Msg {
 	return OpenModalMsg{}
 }
@@ -92,6 +162,78 @@ func ShowErrorModalWithCallback(errorMsg string, callback tea.Cmd) tea.Cmd {
 	}
 }
 
+func ShowSearchModal(query, subreddit string) tea.Cmd {
+	return func() tea.Msg {
+		return ShowSearchModalMsg{Query: query, Subreddit: subreddit}
+	}
+}
+
+func LoadSearch(query, subreddit string) tea.Cmd {
+	return func() tea.Msg {
+		return LoadSearchMsg{Query: query, Subreddit: subreddit}
+	}
+}
+
+func ShowPostSortModal(kind model.PostsKind, sort model.PostSort) tea.Cmd {
+	return func() tea.Msg {
+		return ShowPostSortModalMsg{Kind: kind, Sort: sort}
+	}
+}
+
+func SortPosts(kind model.PostsKind, sort model.PostSort) tea.Cmd {
+	return func() tea.Msg {
+		return SortPostsMsg{Kind: kind, Sort: sort}
+	}
+}
+
+func ShowCommentSortModal(sort string) tea.Cmd {
+	return func() tea.Msg {
+		return ShowCommentSortModalMsg(sort)
+	}
+}
+
+func SortComments(sort string) tea.Cmd {
+	return func() tea.Msg {
+		return SortCommentsMsg(sort)
+	}
+}
+
+func LoadMoreComments(index int, stub model.Comment) tea.Cmd {
+	return func() tea.Msg {
+		return LoadMoreCommentsMsg{Index: index, Stub: stub}
+	}
+}
+
+// Remove the post with the given fullname from the posts pages
+func HidePost(fullname string) tea.Cmd {
+	return func() tea.Msg {
+		return HidePostMsg(fullname)
+	}
+}
+
+// Put a post back after hiding it failed, showing why
+func RollbackHide(fullname, errorMsg string) tea.Msg {
+	return tea.Batch(
+		func() tea.Msg { return UnhidePostMsg(fullname) },
+		ShowErrorModal(errorMsg),
+	)()
+}
+
+// Undo an optimistic vote or save after it failed, showing why
+func RollbackPost(post model.Post, errorMsg string) tea.Msg {
+	return tea.Batch(
+		func() tea.Msg { return RollbackPostMsg(post) },
+		ShowErrorModal(errorMsg),
+	)()
+}
+
+func RollbackComment(comment model.Comment, errorMsg string) tea.Msg {
+	return tea.Batch(
+		func() tea.Msg { return RollbackCommentMsg(comment) },
+		ShowErrorModal(errorMsg),
+	)()
+}
+
 func HideSpinnerModal() tea.Msg {
 	return ExitModalMsg{}
 }
@@ -0,0 +1,40 @@
+package modal
+
+import (
+	"fmt"
+	"reddittui/components/messages"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Yes or no question, running OnConfirm after y is pressed and OnCancel after any other key
+type ConfirmModal struct {
+	Message   string
+	OnConfirm tea.Cmd
+	OnCancel  tea.Cmd
+}
+
+func NewConfirmModal() ConfirmModal {
+	return ConfirmModal{}
+}
+
+func (c ConfirmModal) View() string {
+	titleView := quitTitleStyle.Render(c.Message)
+	yesNoView := quitYesNoStyle.Render(yesNoMsg)
+	return fmt.Sprintf("%s  %s", titleView, yesNoView)
+}
+
+func (c ConfirmModal) Update(msg tea.Msg) (ConfirmModal, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		// Close the modal first so the page is focused before the callback loads anything
+		switch msg.String() {
+		case "y", "Y":
+			return c, tea.Sequence(messages.ExitModal, c.OnConfirm)
+		default:
+			return c, tea.Sequence(messages.ExitModal, c.OnCancel)
+		}
+	}
+
+	return c, nil
+}
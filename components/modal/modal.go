@@ -3,6 +3,7 @@ package modal
 import (
 	"reddittui/components/colors"
 	"reddittui/components/messages"
+	"reddittui/model"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
@@ -17,6 +18,10 @@ const (
 	searching
 	quitting
 	showingError
+	sortingPosts
+	sortingComments
+	searchingPosts
+	confirming
 )
 
 var modalStyle = lipgloss.NewStyle().
@@ -26,22 +31,30 @@ var modalStyle = lipgloss.NewStyle().
 	Margin(1, 1)
 
 type ModalManager struct {
-	quit       QuitModal
-	search     SubredditSearchModal
-	spinner    SpinnerModal
-	errorModal ErrorModal
-	state      SessionState
-	style      lipgloss.Style
-	onClose    tea.Cmd
+	quit        QuitModal
+	search      SubredditSearchModal
+	postSearch  PostSearchModal
+	spinner     SpinnerModal
+	errorModal  ErrorModal
+	postSort    PostSortModal
+	commentSort CommentSortModal
+	confirm     ConfirmModal
+	state       SessionState
+	style       lipgloss.Style
+	onClose     tea.Cmd
 }
 
-func NewModalManager() ModalManager {
+func NewModalManager(feeds []model.Feed) ModalManager {
 	return ModalManager{
-		quit:       NewQuitModal(),
-		search:     NewSubredditSearchModal(),
-		spinner:    NewSpinnerModal(),
-		errorModal: NewErrorModal(),
-		style:      modalStyle,
+		quit:        NewQuitModal(),
+		search:      NewSubredditSearchModal(feeds),
+		postSearch:  NewPostSearchModal(),
+		spinner:     NewSpinnerModal(),
+		errorModal:  NewErrorModal(),
+		postSort:    NewPostSortModal(),
+		commentSort: NewCommentSortModal(),
+		confirm:     NewConfirmModal(),
+		style:       modalStyle,
 	}
 }
 
@@ -78,14 +91,34 @@ func (m ModalManager) handleGlobalMessages(msg tea.Msg) (ModalManager, tea.Cmd)
 	case messages.ShowErrorModalMsg:
 		return m, m.SetErrorWithCallback(msg.ErrorMsg, msg.OnClose)
 
+	case messages.ShowSearchModalMsg:
+		return m, m.SetSearchingPosts(msg.Query, msg.Subreddit)
+
+	case messages.ShowPostSortModalMsg:
+		return m, m.SetSortingPosts(msg.Kind, msg.Sort)
+
+	case messages.ShowCommentSortModalMsg:
+		return m, m.SetSortingComments(string(msg))
+
+	case messages.UpdateSubscriptionsMsg:
+		m.search.SetSubscriptions(msg)
+		return m, nil
+
+	case messages.ShowConfirmModalMsg:
+		return m, m.SetConfirming(msg.Message, msg.OnConfirm, msg.OnCancel)
+
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "esc", "q":
 			if m.state == defaultState {
 				return m, m.SetQuitting()
 			}
-		case "s", "S":
-			return m, m.SetSearching()
+		case "s":
+			// Open modals, such as the search inputs and confirmations, keep their s keystrokes. S saves
+			// posts and comments, so it is left to the pages
+			if m.state == defaultState {
+				return m, m.SetSearching()
+			}
 		}
 	}
 
@@ -108,6 +141,18 @@ func (m ModalManager) handleFocusedMessages(msg tea.Msg) (ModalManager, tea.Cmd)
 	case showingError:
 		m.errorModal, cmd = m.errorModal.Update(msg)
 		return m, cmd
+	case searchingPosts:
+		m.postSearch, cmd = m.postSearch.Update(msg)
+		return m, cmd
+	case sortingPosts:
+		m.postSort, cmd = m.postSort.Update(msg)
+		return m, cmd
+	case sortingComments:
+		m.commentSort, cmd = m.commentSort.Update(msg)
+		return m, cmd
+	case confirming:
+		m.confirm, cmd = m.confirm.Update(msg)
+		return m, cmd
 	default:
 		return m, nil
 	}
@@ -123,6 +168,14 @@ func (m ModalManager) View(background Viewer) string {
 		return PlaceModal(m.search, background, lipgloss.Center, lipgloss.Center, m.style)
 	case showingError:
 		return PlaceModal(m.errorModal, background, lipgloss.Center, lipgloss.Center, m.style)
+	case searchingPosts:
+		return PlaceModal(m.postSearch, background, lipgloss.Center, lipgloss.Center, m.style)
+	case sortingPosts:
+		return PlaceModal(m.postSort, background, lipgloss.Center, lipgloss.Center, m.style)
+	case sortingComments:
+		return PlaceModal(m.commentSort, background, lipgloss.Center, lipgloss.Center, m.style)
+	case confirming:
+		return PlaceModal(m.confirm, background, lipgloss.Center, lipgloss.Center, m.style)
 	default:
 		// This sometimes happens when loading completes before the loading modal finishes rendering
 		return ""
@@ -131,6 +184,7 @@ func (m ModalManager) View(background Viewer) string {
 
 func (m *ModalManager) SetSize(w, h int) {
 	m.search.SetSize(w, h)
+	m.postSearch.SetSize(w, h)
 
 	modalSize := int((float64(w) * (2)) / 3.0)
 	m.style = m.style.MaxWidth(modalSize)
@@ -139,6 +193,7 @@ func (m *ModalManager) SetSize(w, h int) {
 func (m *ModalManager) Blur() tea.Cmd {
 	m.state = defaultState
 	m.search.Blur()
+	m.postSearch.Blur()
 
 	onClose := m.onClose
 	m.onClose = nil
@@ -162,6 +217,30 @@ func (m *ModalManager) SetQuitting() tea.Cmd {
 	return messages.OpenModal
 }
 
+func (m *ModalManager) SetSearchingPosts(query, subreddit string) tea.Cmd {
+	m.state = searchingPosts
+	m.postSearch.SetSearch(query, subreddit)
+	return messages.OpenModal
+}
+
+func (m *ModalManager) SetSortingPosts(kind model.PostsKind, sort model.PostSort) tea.Cmd {
+	m.state = sortingPosts
+	m.postSort.SetSort(kind, sort)
+	return messages.OpenModal
+}
+
+func (m *ModalManager) SetSortingComments(sort string) tea.Cmd {
+	m.state = sortingComments
+	m.commentSort.SetSort(sort)
+	return messages.OpenModal
+}
+
+func (m *ModalManager) SetConfirming(message string, onConfirm, onCancel tea.Cmd) tea.Cmd {
+	m.state = confirming
+	m.confirm = ConfirmModal{Message: message, OnConfirm: onConfirm, OnCancel: onCancel}
+	return messages.OpenModal
+}
+
 func (m *ModalManager) SetError(errorMsg string) tea.Cmd {
 	m.state = showingError
 	m.errorModal.ErrorMsg = errorMsg
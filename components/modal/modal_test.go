@@ -0,0 +1,33 @@
+package modal
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func keyPress(key string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+func TestSaveKeyDoesNotOpenSearch(t *testing.T) {
+	m := NewModalManager(nil)
+	m, _ = m.Update(keyPress("S"))
+	if m.state != defaultState {
+		t.Errorf("state after S = %d, want %d", m.state, defaultState)
+	}
+
+	m, _ = m.Update(keyPress("s"))
+	if m.state != searching {
+		t.Errorf("state after s = %d, want %d", m.state, searching)
+	}
+}
+
+func TestSearchKeyKeepsOpenModal(t *testing.T) {
+	m := NewModalManager(nil)
+	m.SetConfirming("Continue?", nil, nil)
+	m, _ = m.Update(keyPress("s"))
+	if m.state != confirming {
+		t.Errorf("state after s = %d, want %d", m.state, confirming)
+	}
+}
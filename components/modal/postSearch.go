@@ -0,0 +1,119 @@
+package modal
+
+import (
+	"fmt"
+	"reddittui/components/messages"
+	"reddittui/utils"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	postSearchHelpText      = "Search reddit:"
+	postSearchScopeHelpText = "Search %s:"
+	postSearchTabHelpText   = "tab to search %s"
+	postSearchPlaceholder   = "search"
+	allOfReddit             = "all of reddit"
+)
+
+// Text input used to search posts. When opened from a subreddit the search is limited to that
+// subreddit, tab toggles between searching the subreddit and all of reddit
+type PostSearchModal struct {
+	textinput.Model
+	subreddit  string
+	restricted bool
+	style      lipgloss.Style
+}
+
+func NewPostSearchModal() PostSearchModal {
+	searchTextInput := textinput.New()
+	searchTextInput.Placeholder = postSearchPlaceholder
+	searchTextInput.CharLimit = 256
+
+	return PostSearchModal{
+		Model: searchTextInput,
+		style: lipgloss.NewStyle(),
+	}
+}
+
+func (s PostSearchModal) Init() tea.Cmd {
+	return nil
+}
+
+func (s PostSearchModal) Update(msg tea.Msg) (PostSearchModal, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter":
+			query := strings.TrimSpace(s.Value())
+			if len(query) == 0 {
+				return s, nil
+			}
+
+			return s, messages.LoadSearch(query, s.scope())
+		case "tab":
+			s.restricted = !s.restricted && len(s.subreddit) > 0
+			return s, nil
+		case "esc":
+			return s, messages.ExitModal
+		}
+	}
+
+	var cmd tea.Cmd
+	s.Model, cmd = s.Model.Update(msg)
+	return s, cmd
+}
+
+func (s PostSearchModal) View() string {
+	titleText := postSearchHelpText
+	if s.restricted {
+		titleText = fmt.Sprintf(postSearchScopeHelpText, utils.NormalizeSubreddit(s.subreddit))
+	}
+
+	views := []string{
+		searchHelpStyle.Render(titleText),
+		searchModelStyle.Render(s.Model.View()),
+	}
+
+	if len(s.subreddit) > 0 {
+		other := allOfReddit
+		if !s.restricted {
+			other = utils.NormalizeSubreddit(s.subreddit)
+		}
+
+		views = append(views, "", searchHelpStyle.Render(fmt.Sprintf(postSearchTabHelpText, other)))
+	}
+
+	joined := lipgloss.JoinVertical(lipgloss.Left, views...)
+	return s.style.Render(joined)
+}
+
+func (s *PostSearchModal) SetSize(w, h int) {
+	searchW := min(w-s.style.GetHorizontalFrameSize(), defaultSearchWidth)
+	s.style = s.style.Width(searchW)
+}
+
+// Prepare the input for a new search, optionally scoped to a subreddit
+func (s *PostSearchModal) SetSearch(query, subreddit string) {
+	s.subreddit = subreddit
+	s.restricted = len(subreddit) > 0
+	s.SetValue(query)
+	s.CursorEnd()
+	s.Focus()
+}
+
+func (s *PostSearchModal) Blur() {
+	s.Model.Blur()
+	s.Reset()
+}
+
+func (s PostSearchModal) scope() string {
+	if s.restricted {
+		return s.subreddit
+	}
+
+	return ""
+}
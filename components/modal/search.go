@@ -1,8 +1,12 @@
 package modal
 
 import (
+	"fmt"
 	"reddittui/components/colors"
 	"reddittui/components/messages"
+	"reddittui/model"
+	"slices"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -11,6 +15,9 @@ import (
 
 const (
 	searchHelpText     = "Choose a subreddit:"
+	feedsHelpText      = "Feeds: "
+	subscriptionsText  = "Subscriptions: "
+	maxSubscriptions   = 20
 	searchPlaceholder  = "subreddit"
 	defaultSearchWidth = 35
 )
@@ -18,24 +25,45 @@ const (
 var (
 	searchHelpStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text)).Italic(true)
 	searchModelStyle = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Purple))
+	feedNameStyle    = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Blue))
 )
 
 type SubredditSearchModal struct {
 	textinput.Model
-	style lipgloss.Style
+	feeds         []model.Feed
+	subscriptions []string
+	style         lipgloss.Style
 }
 
-func NewSubredditSearchModal() SubredditSearchModal {
+func NewSubredditSearchModal(feeds []model.Feed) SubredditSearchModal {
 	searchTextInput := textinput.New()
 	searchTextInput.Placeholder = searchPlaceholder
 	searchTextInput.ShowSuggestions = true
-	searchTextInput.SetSuggestions(subredditSuggestions)
-	searchTextInput.CharLimit = 30
+	searchTextInput.CharLimit = 100
 
-	return SubredditSearchModal{
+	s := SubredditSearchModal{
 		Model: searchTextInput,
+		feeds: feeds,
 		style: lipgloss.NewStyle(),
 	}
+
+	s.updateSuggestions()
+	return s
+}
+
+// Subreddits the logged in user is subscribed to are listed and suggested before the defaults
+func (s *SubredditSearchModal) SetSubscriptions(subscriptions []string) {
+	s.subscriptions = subscriptions
+	s.updateSuggestions()
+}
+
+func (s *SubredditSearchModal) updateSuggestions() {
+	var feedNames []string
+	for _, feed := range s.feeds {
+		feedNames = append(feedNames, feed.Name)
+	}
+
+	s.SetSuggestions(slices.Concat(feedNames, s.subscriptions, subredditSuggestions))
 }
 
 func (s SubredditSearchModal) Init() tea.Cmd {
@@ -47,6 +75,11 @@ func (s SubredditSearchModal) Update(msg tea.Msg) (SubredditSearchModal, tea.Cmd
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "enter":
+			// Feeds take precedence over subreddits with the same name
+			if feed, ok := s.findFeed(s.Value()); ok {
+				return s, messages.LoadFeed(feed)
+			}
+
 			return s, messages.LoadSubreddit(s.Value())
 		case "esc":
 			return s, messages.ExitModal
@@ -61,8 +94,43 @@ func (s SubredditSearchModal) Update(msg tea.Msg) (SubredditSearchModal, tea.Cmd
 func (s SubredditSearchModal) View() string {
 	titleView := searchHelpStyle.Render(searchHelpText)
 	modelView := searchModelStyle.Render(s.Model.View())
-	joined := lipgloss.JoinVertical(lipgloss.Left, titleView, modelView)
-	return s.style.Render(joined)
+	views := []string{titleView, modelView}
+
+	if len(s.feeds) > 0 {
+		var feedNames []string
+		for _, feed := range s.feeds {
+			feedNames = append(feedNames, feedNameStyle.Render(feed.Name))
+		}
+
+		views = append(views, "", searchHelpStyle.Render(feedsHelpText)+strings.Join(feedNames, ", "))
+	}
+
+	if len(s.subscriptions) > 0 {
+		var names []string
+		for _, name := range s.subscriptions[:min(len(s.subscriptions), maxSubscriptions)] {
+			names = append(names, feedNameStyle.Render(name))
+		}
+
+		subscriptionsView := searchHelpStyle.Render(subscriptionsText) + strings.Join(names, ", ")
+		if remaining := len(s.subscriptions) - maxSubscriptions; remaining > 0 {
+			subscriptionsView += searchHelpStyle.Render(fmt.Sprintf(" and %d more", remaining))
+		}
+
+		views = append(views, "", subscriptionsView)
+	}
+
+	return s.style.Render(lipgloss.JoinVertical(lipgloss.Left, views...))
+}
+
+func (s SubredditSearchModal) findFeed(name string) (model.Feed, bool) {
+	name = strings.TrimSpace(name)
+	for _, feed := range s.feeds {
+		if strings.EqualFold(feed.Name, name) {
+			return feed, true
+		}
+	}
+
+	return model.Feed{}, false
 }
 
 func (s *SubredditSearchModal) SetSize(w, h int) {
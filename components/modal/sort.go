@@ -0,0 +1,242 @@
+package modal
+
+import (
+	"fmt"
+	"reddittui/components/colors"
+	"reddittui/components/messages"
+	"reddittui/model"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	sortHelpText        = "Sort posts by:"
+	searchSortHelpText  = "Sort search results by:"
+	timeframeHelpText   = "Show %s posts from:"
+	commentSortHelpText = "Sort comments by:"
+)
+
+var (
+	sortTitleStyle    = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text)).Italic(true)
+	sortKeyStyle      = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Purple)).Bold(true)
+	sortOptionStyle   = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text))
+	sortSelectedStyle = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Blue)).Underline(true)
+)
+
+type sortOption struct {
+	key   string
+	value string
+	label string
+}
+
+var postSortOptions = []sortOption{
+	{"h", model.SortHot, "hot"},
+	{"n", model.SortNew, "new"},
+	{"t", model.SortTop, "top"},
+	{"r", model.SortRising, "rising"},
+	{"c", model.SortControversial, "controversial"},
+}
+
+var userSortOptions = []sortOption{
+	{"n", model.SortNew, "new"},
+	{"h", model.SortHot, "hot"},
+	{"t", model.SortTop, "top"},
+	{"c", model.SortControversial, "controversial"},
+}
+
+var searchSortOptions = []sortOption{
+	{"r", model.SortRelevance, "relevance"},
+	{"h", model.SortHot, "hot"},
+	{"t", model.SortTop, "top"},
+	{"n", model.SortNew, "new"},
+	{"c", model.SortComments, "comments"},
+}
+
+var timeframeOptions = []sortOption{
+	{"h", model.TimeframeHour, "hour"},
+	{"d", model.TimeframeDay, "day"},
+	{"w", model.TimeframeWeek, "week"},
+	{"m", model.TimeframeMonth, "month"},
+	{"y", model.TimeframeYear, "year"},
+	{"a", model.TimeframeAll, "all time"},
+}
+
+var commentSortOptions = []sortOption{
+	{"b", model.CommentSortBest, "best"},
+	{"t", model.CommentSortTop, "top"},
+	{"n", model.CommentSortNew, "new"},
+	{"c", model.CommentSortControversial, "controversial"},
+	{"o", model.CommentSortOld, "old"},
+	{"q", model.CommentSortQA, "Q&A"},
+}
+
+// Two step picker, first choose the sort then the time range for sorts that support one.
+// Search results support a time range for every sort
+type PostSortModal struct {
+	kind           model.PostsKind
+	current        model.PostSort
+	sort           string
+	showTimeframes bool
+}
+
+func NewPostSortModal() PostSortModal {
+	return PostSortModal{}
+}
+
+func (s PostSortModal) Init() tea.Cmd {
+	return nil
+}
+
+func (s PostSortModal) Update(msg tea.Msg) (PostSortModal, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		keypress := msg.String()
+		if keypress == "esc" {
+			return s, messages.ExitModal
+		}
+
+		if !s.showTimeframes {
+			option, ok := findSortOption(s.sortOptions(), keypress)
+			if !ok {
+				return s, nil
+			}
+
+			sort := model.NewPostSort(option.value, "")
+			if !s.isSearch() && !sort.HasTimeframe() {
+				return s, messages.SortPosts(s.kind, sort)
+			}
+
+			s.sort = option.value
+			s.showTimeframes = true
+			return s, nil
+		}
+
+		option, ok := findSortOption(timeframeOptions, keypress)
+		if !ok {
+			return s, nil
+		}
+
+		if s.isSearch() {
+			return s, messages.SortPosts(s.kind, model.NewSearchSort(s.sort, option.value))
+		}
+
+		return s, messages.SortPosts(s.kind, model.NewPostSort(s.sort, option.value))
+	}
+
+	return s, nil
+}
+
+func (s PostSortModal) View() string {
+	if s.showTimeframes {
+		titleView := sortTitleStyle.Render(fmt.Sprintf(timeframeHelpText, s.sort))
+		optionsView := renderSortOptions(timeframeOptions, s.current.Timeframe)
+		return lipgloss.JoinVertical(lipgloss.Left, titleView, "", optionsView)
+	}
+
+	helpText := sortHelpText
+	if s.isSearch() {
+		helpText = searchSortHelpText
+	}
+
+	titleView := sortTitleStyle.Render(helpText)
+	optionsView := renderSortOptions(s.sortOptions(), s.current.Sort)
+	return lipgloss.JoinVertical(lipgloss.Left, titleView, "", optionsView)
+}
+
+func (s PostSortModal) isSearch() bool {
+	return s.kind == model.SearchPosts
+}
+
+func (s PostSortModal) sortOptions() []sortOption {
+	switch s.kind {
+	case model.SearchPosts:
+		return searchSortOptions
+	case model.UserPosts:
+		return userSortOptions
+	default:
+		return postSortOptions
+	}
+}
+
+func (s *PostSortModal) SetSort(kind model.PostsKind, current model.PostSort) {
+	if current.Sort == "" && kind == model.SearchPosts {
+		current = model.DefaultSearchSort()
+	} else if current.Sort == "" && kind == model.UserPosts {
+		current = model.DefaultUserSort()
+	} else if current.Sort == "" {
+		current = model.DefaultPostSort()
+	}
+
+	s.kind = kind
+	s.current = current
+	s.sort = ""
+	s.showTimeframes = false
+}
+
+type CommentSortModal struct {
+	current string
+}
+
+func NewCommentSortModal() CommentSortModal {
+	return CommentSortModal{}
+}
+
+func (s CommentSortModal) Init() tea.Cmd {
+	return nil
+}
+
+func (s CommentSortModal) Update(msg tea.Msg) (CommentSortModal, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		keypress := msg.String()
+		if keypress == "esc" {
+			return s, messages.ExitModal
+		}
+
+		if option, ok := findSortOption(commentSortOptions, keypress); ok {
+			return s, messages.SortComments(option.value)
+		}
+	}
+
+	return s, nil
+}
+
+func (s CommentSortModal) View() string {
+	titleView := sortTitleStyle.Render(commentSortHelpText)
+	optionsView := renderSortOptions(commentSortOptions, s.current)
+	return lipgloss.JoinVertical(lipgloss.Left, titleView, "", optionsView)
+}
+
+func (s *CommentSortModal) SetSort(current string) {
+	if current == "" {
+		current = model.CommentSortBest
+	}
+
+	s.current = current
+}
+
+func findSortOption(options []sortOption, key string) (sortOption, bool) {
+	for _, option := range options {
+		if option.key == key {
+			return option, true
+		}
+	}
+
+	return sortOption{}, false
+}
+
+func renderSortOptions(options []sortOption, selected string) string {
+	var views []string
+	for _, option := range options {
+		labelStyle := sortOptionStyle
+		if option.value == selected {
+			labelStyle = sortSelectedStyle
+		}
+
+		views = append(views, fmt.Sprintf("%s %s", sortKeyStyle.Render(option.key), labelStyle.Render(option.label)))
+	}
+
+	return strings.Join(views, "   ")
+}
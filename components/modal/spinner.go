@@ -3,6 +3,7 @@ package modal
 import (
 	"fmt"
 	"reddittui/components/colors"
+	"reddittui/components/messages"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
@@ -34,6 +35,15 @@ func (s SpinnerModal) Init() tea.Cmd {
 }
 
 func (s SpinnerModal) Update(msg tea.Msg) (SpinnerModal, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc", "backspace":
+			return s, messages.CancelLoading
+		}
+
+		return s, nil
+	}
+
 	var cmd tea.Cmd
 	s.Model, cmd = s.Model.Update(msg)
 	return s, cmd
@@ -1,8 +1,11 @@
 package posts
 
 import (
+	"fmt"
 	"reddittui/components/colors"
+	"reddittui/model"
 	"reddittui/utils"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -16,6 +19,13 @@ var (
 				Background(colors.AdaptiveColors(colors.Blue, colors.Indigo)).
 				Foreground(colors.AdaptiveColors(colors.White, colors.Sand))
 
+	sortStyle = lipgloss.NewStyle().
+			MarginBottom(1).
+			Padding(0, 2).
+			Height(1).
+			Foreground(colors.AdaptiveColor(colors.Lavender)).
+			Italic(true)
+
 	defaultDescriptionStyle = lipgloss.NewStyle().
 				Bold(true).
 				Foreground(colors.AdaptiveColor(colors.Text))
@@ -25,6 +35,10 @@ type PostsHeader struct {
 	DescriptionStyle lipgloss.Style
 	Title            string
 	Description      string
+	Sort             model.PostSort
+	Instance         string
+	Cached           string
+	Offline          bool
 	W                int
 }
 
@@ -39,13 +53,53 @@ func (h *PostsHeader) SetSize(width, height int) {
 
 func (h PostsHeader) View() string {
 	titleView := titleStyle.Render(utils.TruncateString(h.Title, h.W))
+	sortText := h.Sort.String()
+	if h.Offline {
+		sortText = fmt.Sprintf("%s • offline", sortText)
+	} else if len(h.Instance) > 0 {
+		sortText = fmt.Sprintf("%s • via %s", sortText, h.Instance)
+	}
+	if len(h.Cached) > 0 {
+		sortText = fmt.Sprintf("%s • %s", sortText, h.Cached)
+	}
+	sortView := sortStyle.Render(sortText)
+
+	titleAndSortView := lipgloss.JoinHorizontal(lipgloss.Top, titleView, sortView)
 	descriptionView := h.DescriptionStyle.Render(h.Description)
 
-	joinedView := lipgloss.JoinVertical(lipgloss.Left, titleView, descriptionView)
+	joinedView := lipgloss.JoinVertical(lipgloss.Left, titleAndSortView, descriptionView)
 	return headerContainerStyle.Render(joinedView)
 }
 
+func (h *PostsHeader) SetSort(sort model.PostSort) {
+	h.Sort = sort
+}
+
+// Show which server the posts were loaded from when failing over between several
+func (h *PostsHeader) SetInstance(instance string) {
+	h.Instance = instance
+}
+
+func (h *PostsHeader) SetOffline(offline bool) {
+	h.Offline = offline
+}
+
+// Show the age of stale posts while they are refreshed in the background
+func (h *PostsHeader) SetCached(posts model.Posts) {
+	h.Cached = utils.CachedDescription(posts.Stale, posts.Fetched, time.Now())
+}
+
 func (h *PostsHeader) SetContent(title, desc string) {
 	h.Title = utils.NormalizeSubreddit(title)
 	h.Description = desc
 }
+
+func (h *PostsHeader) SetUserContent(user, desc string) {
+	h.Title = utils.NormalizeUser(user)
+	h.Description = desc
+}
+
+func (h *PostsHeader) SetFeedContent(name, desc string) {
+	h.Title = name
+	h.Description = desc
+}
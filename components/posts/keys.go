@@ -3,10 +3,19 @@ package posts
 import "github.com/charmbracelet/bubbles/key"
 
 type postsKeyMap struct {
-	Home   key.Binding
-	Search key.Binding
-	Back   key.Binding
-	Load   key.Binding
+	Home     key.Binding
+	Search   key.Binding
+	Back     key.Binding
+	Load     key.Binding
+	Sort     key.Binding
+	Find     key.Binding
+	User     key.Binding
+	Upvote   key.Binding
+	Downvote key.Binding
+	Save     key.Binding
+	Hide     key.Binding
+	Submit   key.Binding
+	Reveal   key.Binding
 }
 
 var postsKeys = postsKeyMap{
@@ -22,12 +31,39 @@ var postsKeys = postsKeyMap{
 	Load: key.NewBinding(
 		key.WithKeys("L"),
 		key.WithHelp("L", "load more posts")),
+	Sort: key.NewBinding(
+		key.WithKeys("O"),
+		key.WithHelp("O", "sort posts")),
+	Find: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "search posts")),
+	User: key.NewBinding(
+		key.WithKeys("U"),
+		key.WithHelp("U", "view author")),
+	Upvote: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "upvote")),
+	Downvote: key.NewBinding(
+		key.WithKeys("z"),
+		key.WithHelp("z", "downvote")),
+	Save: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "save")),
+	Hide: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "hide")),
+	Submit: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "new post")),
+	Reveal: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "reveal title")),
 }
 
 func (k postsKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Home, k.Search, k.Load}
+	return []key.Binding{k.Home, k.Search, k.Find, k.Load, k.Sort}
 }
 
 func (k postsKeyMap) FullHelp() []key.Binding {
-	return []key.Binding{k.Home, k.Search, k.Back, k.Load}
+	return []key.Binding{k.Home, k.Search, k.Find, k.Back, k.Load, k.Sort, k.User, k.Upvote, k.Downvote, k.Save, k.Hide, k.Submit, k.Reveal}
 }
@@ -1,13 +1,19 @@
 package posts
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"reddittui/client"
+	"reddittui/client/auth"
 	"reddittui/client/common"
 	"reddittui/components/messages"
 	"reddittui/components/styles"
 	"reddittui/model"
+	"reddittui/utils"
+	"slices"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
@@ -19,20 +25,48 @@ const (
 	defaultHeaderDescription = "The front page of the internet"
 	postsErrorText           = "Could not load posts. Please try again in a few moments."
 	subredditNotFoundText    = "Subreddit not found"
+	userNotFoundText         = "User not found"
+	userDescription          = "submitted posts and comments"
+	userEmptyDescription     = "has not submitted any posts or comments yet"
+	searchNotFoundText       = "No results found for"
+	searchDescription        = "search results for \"%s\""
+	morePostsOfflineText     = "More posts are not available offline"
+	searchOfflineText        = "Search results for \"%s\" are not available offline"
+	over18Text               = "%s is marked as over 18. Are you over 18 and want to continue?"
+	cannotVoteText           = "This post cannot be voted on, saved or hidden"
+	submitSubredditText      = "Open a subreddit to submit a post to it"
+	draftErrorText           = "Could not open the editor"
+	missingTitleText         = "Posts need a title. Your draft was kept and opens again the next time you submit."
+	confirmSubmitText        = "Submit %s post \"%s\" to %s?"
+	draftKeptText            = "%s Your draft was kept and opens again the next time you submit."
 )
 
+// Post removed from the page after hiding it, kept so it can be put back if hiding fails
+type hiddenPost struct {
+	post      model.Post
+	listIndex int
+	index     int
+}
+
 type PostsPage struct {
 	Subreddit      string
+	User           string
+	feed           model.Feed
+	query          string
+	sort           model.PostSort
 	posts          model.Posts
 	redditClient   client.RedditClient
 	header         PostsHeader
 	list           list.Model
 	focus          bool
-	Home           bool
+	kind           model.PostsKind
 	containerStyle lipgloss.Style
+	cancel         context.CancelFunc
+	firstPageSize  int
+	hidden         map[string]hiddenPost
 }
 
-func NewPostsPage(redditClient client.RedditClient, home bool) PostsPage {
+func NewPostsPage(redditClient client.RedditClient, kind model.PostsKind) PostsPage {
 	items := list.New(nil, NewPostsDelegate(), 0, 0)
 	items.SetShowTitle(false)
 	items.SetShowStatusBar(false)
@@ -43,7 +77,7 @@ func NewPostsPage(redditClient client.RedditClient, home bool) PostsPage {
 	items.AdditionalFullHelpKeys = postsKeys.FullHelp
 
 	header := NewPostsHeader()
-	if home {
+	if kind == model.HomePosts {
 		header.SetContent(defaultHeaderTitle, defaultHeaderDescription)
 	}
 
@@ -53,8 +87,9 @@ func NewPostsPage(redditClient client.RedditClient, home bool) PostsPage {
 		list:           items,
 		redditClient:   redditClient,
 		header:         header,
-		Home:           home,
+		kind:           kind,
 		containerStyle: containerStyle,
+		hidden:         make(map[string]hiddenPost),
 	}
 }
 
@@ -80,35 +115,88 @@ func (p PostsPage) Update(msg tea.Msg) (PostsPage, tea.Cmd) {
 func (p PostsPage) handleGlobalMessages(msg tea.Msg) (PostsPage, tea.Cmd) {
 	switch msg := msg.(type) {
 	case messages.LoadHomeMsg:
-		if p.Home {
+		if p.kind == model.HomePosts {
 			return p, p.loadHome()
 		}
 
 	case messages.LoadSubredditMsg:
-		if !p.Home {
+		if p.kind == model.SubredditPosts {
 			subreddit := string(msg)
-			return p, p.loadSubreddit(subreddit)
+			p.feed = model.Feed{}
+			p.sort = model.DefaultPostSort()
+			return p, p.loadSubreddit(subreddit, p.sort)
+		}
+
+	case messages.LoadFeedMsg:
+		if p.kind == model.SubredditPosts {
+			p.feed = model.Feed(msg)
+			p.sort = p.feed.Sort
+			return p, p.loadSubreddit(p.feed.Subreddit(), p.sort)
+		}
+
+	case messages.LoadSearchMsg:
+		if p.kind == model.SearchPosts {
+			p.sort = model.DefaultSearchSort()
+			return p, p.loadSearch(msg.Query, msg.Subreddit, p.sort)
+		}
+
+	case messages.LoadUserMsg:
+		if p.kind == model.UserPosts {
+			user := string(msg)
+			p.sort = model.DefaultUserSort()
+			return p, p.loadUser(user, p.sort)
 		}
 
 	case messages.LoadMorePostsMsg:
-		isHome := bool(msg)
-		if p.Home == isHome {
+		if p.kind == model.PostsKind(msg) {
 			return p, p.loadMorePosts()
 		}
 
+	case messages.SortPostsMsg:
+		if p.kind == msg.Kind {
+			p.sort = msg.Sort
+			return p, p.loadSorted(msg.Sort)
+		}
+
+	case messages.CancelLoadingMsg:
+		p.cancelLoading()
+
 	case messages.UpdatePostsMsg:
 		posts := model.Posts(msg)
-		if posts.IsHome == p.Home {
+		if posts.Kind() == p.kind {
+			p.cancelLoading()
 			p.updatePosts(posts)
-			return p, messages.LoadingComplete
+
+			cmds := []tea.Cmd{messages.LoadingComplete, p.prefetchComments()}
+			if posts.Stale {
+				cmds = append(cmds, p.revalidatePosts(posts))
+			}
+
+			return p, tea.Batch(cmds...)
+		}
+
+	case messages.RefreshPostsMsg:
+		posts := model.Posts(msg)
+		if posts.Kind() == p.kind && p.posts.Stale && isSameListing(p.posts, posts) {
+			p.refreshPosts(posts)
 		}
 
 	case messages.AddMorePostsMsg:
 		posts := model.Posts(msg)
-		if posts.IsHome == p.Home {
+		if posts.Kind() == p.kind {
+			p.cancelLoading()
 			p.addPosts(posts)
 			return p, messages.LoadingComplete
 		}
+
+	case messages.HidePostMsg:
+		p.removePost(string(msg))
+
+	case messages.UnhidePostMsg:
+		p.unhidePost(string(msg))
+
+	case messages.RollbackPostMsg:
+		p.replacePost(model.Post(msg))
 	}
 
 	return p, nil
@@ -119,12 +207,12 @@ func (p PostsPage) handleFocusedMessages(msg tea.Msg) (PostsPage, tea.Cmd) {
 	case tea.KeyMsg:
 		switch keypress := msg.String(); keypress {
 		case "enter", "right", "l":
-			loadCommentsCmd := func() tea.Msg {
-				post := p.posts.Posts[p.list.Index()]
-				return messages.LoadCommentsMsg(post.CommentsUrl)
+			post, ok := p.selectedPost()
+			if !ok {
+				return p, nil
 			}
 
-			return p, loadCommentsCmd
+			return p, messages.LoadComments(post.CommentsUrl)
 
 		case "q", "Q":
 			// Ignore q keystrokes to list.Modal. since it will default to sending a Quit message
@@ -132,18 +220,57 @@ func (p PostsPage) handleFocusedMessages(msg tea.Msg) (PostsPage, tea.Cmd) {
 			return p, nil
 
 		case "L":
-			return p, messages.LoadMorePosts(p.Home)
+			return p, messages.LoadMorePosts(p.kind)
 
 		case "H":
 			return p, messages.LoadHome
 
+		case "O":
+			return p, messages.ShowPostSortModal(p.kind, p.sort)
+
+		case "/":
+			return p, p.showSearchModal()
+
+		case "U":
+			post, ok := p.selectedPost()
+			if ok && !model.IsDeletedAuthor(post.Author) {
+				return p, messages.LoadUser(post.Author)
+			}
+
+			return p, nil
+
+		case "a":
+			return p, p.vote(model.Upvote)
+
+		case "z":
+			return p, p.vote(model.Downvote)
+
+		case "S":
+			return p, p.toggleSave()
+
+		case "x":
+			return p, p.hide()
+
+		case "N":
+			return p, p.submit()
+
+		case "v":
+			p.revealTitle()
+			return p, nil
+
 		case "esc", "backspace", "left", "h":
 			return p, messages.GoBack
 		}
 	}
 
 	var cmd tea.Cmd
+	cursor := p.list.Index()
 	p.list, cmd = p.list.Update(msg)
+
+	if p.list.Index() != cursor {
+		return p, tea.Batch(cmd, p.prefetchComments())
+	}
+
 	return p, cmd
 }
 
@@ -184,12 +311,32 @@ func (p *PostsPage) resizeComponents() {
 	p.list.SetSize(listWidth, listHeight)
 }
 
+// Cancel the request still in flight, if any, and return a context for the next one
+func (p *PostsPage) startLoading() context.Context {
+	p.cancelLoading()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	return ctx
+}
+
+func (p *PostsPage) cancelLoading() {
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+}
+
 func (p *PostsPage) loadHome() tea.Cmd {
+	sort := p.sort
+	ctx := p.startLoading()
 	return func() tea.Msg {
-		posts, err := p.redditClient.GetHomePosts("")
-		if err != nil {
-			slog.Error(postsErrorText, "error", err)
-			return messages.ShowErrorModalMsg{ErrorMsg: postsErrorText}
+		posts, err := p.redditClient.GetHomePosts(ctx, sort, "")
+		if ctx.Err() != nil {
+			// Loading was cancelled, the user has already gone back to the previous page
+			return nil
+		} else if err != nil {
+			return showPostsError(err, defaultHeaderTitle)
 		}
 
 		return messages.UpdatePostsMsg(posts)
@@ -197,6 +344,7 @@ func (p *PostsPage) loadHome() tea.Cmd {
 }
 
 func (p *PostsPage) loadMorePosts() tea.Cmd {
+	ctx := p.startLoading()
 	return func() tea.Msg {
 		var (
 			posts model.Posts
@@ -208,46 +356,220 @@ func (p *PostsPage) loadMorePosts() tea.Cmd {
 			return messages.ShowErrorModalMsg{ErrorMsg: postsErrorText}
 		}
 
-		if p.posts.IsHome {
-			posts, err = p.redditClient.GetHomePosts(p.posts.After)
-		} else {
-			posts, err = p.redditClient.GetSubredditPosts(p.Subreddit, p.posts.After)
+		switch p.posts.Kind() {
+		case model.HomePosts:
+			posts, err = p.redditClient.GetHomePosts(ctx, p.posts.Sort, p.posts.After)
+		case model.SearchPosts:
+			posts, err = p.redditClient.SearchPosts(ctx, p.query, p.Subreddit, p.posts.Sort, p.posts.After)
+		case model.UserPosts:
+			posts, err = p.redditClient.GetUserPosts(ctx, p.User, p.posts.Sort, p.posts.After)
+		default:
+			posts, err = p.redditClient.GetSubredditPosts(ctx, p.Subreddit, p.posts.Sort, p.posts.After)
 		}
 
-		if err != nil {
-			slog.Error(postsErrorText, "error", err)
-			return messages.ShowErrorModalMsg{ErrorMsg: postsErrorText}
+		if ctx.Err() != nil {
+			return nil
+		} else if errors.Is(err, common.ErrOffline) {
+			return messages.ShowErrorModalMsg{ErrorMsg: morePostsOfflineText}
+		} else if errors.Is(err, common.ErrOver18) {
+			return p.confirmOver18(p.subject(), messages.LoadMorePostsMsg(p.kind))
+		} else if err != nil {
+			return showPostsError(err, p.subject())
 		}
 
 		return messages.AddMorePostsMsg(posts)
 	}
 }
 
-func (p PostsPage) loadSubreddit(subreddit string) tea.Cmd {
+func (p *PostsPage) loadSubreddit(subreddit string, sort model.PostSort) tea.Cmd {
+	var retry tea.Msg = messages.SortPostsMsg{Kind: p.kind, Sort: sort}
+	if p.Subreddit != subreddit {
+		// The subreddit has not been shown yet, load it again from scratch
+		retry = messages.LoadSubredditMsg(subreddit)
+		if len(p.feed.Name) > 0 {
+			retry = messages.LoadFeedMsg(p.feed)
+		}
+	}
+
+	ctx := p.startLoading()
 	return func() tea.Msg {
-		posts, err := p.redditClient.GetSubredditPosts(subreddit, "")
-		if err == common.ErrNotFound {
+		posts, err := p.redditClient.GetSubredditPosts(ctx, subreddit, sort, "")
+		if ctx.Err() != nil {
+			return nil
+		} else if errors.Is(err, common.ErrNotFound) {
 			slog.Error(subredditNotFoundText, "error", err, "subreddit", subreddit)
 			return messages.ShowErrorModalMsg{ErrorMsg: fmt.Sprintf("%s: %s", subredditNotFoundText, subreddit)}
+		} else if errors.Is(err, common.ErrOver18) {
+			return p.confirmOver18(utils.NormalizeSubreddit(subreddit), retry)
 		} else if err != nil {
-			slog.Error(postsErrorText, "error", err)
-			return messages.ShowErrorModalMsg{ErrorMsg: postsErrorText}
+			return showPostsError(err, utils.NormalizeSubreddit(subreddit))
+		}
+
+		return messages.UpdatePostsMsg(posts)
+	}
+}
+
+func (p *PostsPage) loadUser(user string, sort model.PostSort) tea.Cmd {
+	ctx := p.startLoading()
+	return func() tea.Msg {
+		posts, err := p.redditClient.GetUserPosts(ctx, user, sort, "")
+		if ctx.Err() != nil {
+			return nil
+		} else if errors.Is(err, common.ErrNotFound) {
+			slog.Error(userNotFoundText, "error", err, "user", user)
+			return messages.ShowErrorModalMsg{ErrorMsg: fmt.Sprintf("%s: %s", userNotFoundText, user)}
+		} else if errors.Is(err, common.ErrOver18) {
+			return p.confirmOver18(utils.NormalizeUser(user), messages.LoadUserMsg(user))
+		} else if err != nil {
+			return showPostsError(err, utils.NormalizeUser(user))
+		}
+
+		return messages.UpdatePostsMsg(posts)
+	}
+}
+
+func (p *PostsPage) loadSearch(query, subreddit string, sort model.PostSort) tea.Cmd {
+	ctx := p.startLoading()
+	return func() tea.Msg {
+		posts, err := p.redditClient.SearchPosts(ctx, query, subreddit, sort, "")
+		if ctx.Err() != nil {
+			return nil
+		} else if errors.Is(err, common.ErrNotFound) {
+			slog.Error(searchNotFoundText, "error", err, "query", query, "subreddit", subreddit)
+			return messages.ShowErrorModalMsg{ErrorMsg: fmt.Sprintf("%s: %s", searchNotFoundText, query)}
+		} else if errors.Is(err, common.ErrOffline) {
+			return messages.ShowErrorModalMsg{ErrorMsg: fmt.Sprintf(searchOfflineText, query)}
+		} else if errors.Is(err, common.ErrOver18) {
+			return p.confirmOver18(fmt.Sprintf("\"%s\"", query), messages.LoadSearchMsg{Query: query, Subreddit: subreddit})
+		} else if err != nil {
+			subject := defaultHeaderTitle
+			if len(subreddit) > 0 {
+				subject = utils.NormalizeSubreddit(subreddit)
+			}
+
+			return showPostsError(err, subject)
 		}
 
 		return messages.UpdatePostsMsg(posts)
 	}
 }
 
+// Refresh stale posts in the background. The page keeps showing the stale posts if this fails
+func (p PostsPage) revalidatePosts(stale model.Posts) tea.Cmd {
+	return func() tea.Msg {
+		posts, err := p.redditClient.RevalidatePosts(context.Background(), stale)
+		if err != nil {
+			slog.Warn("Could not refresh stale posts", "error", err)
+			return nil
+		}
+
+		return messages.RefreshPostsMsg(posts)
+	}
+}
+
+// Load comments for the posts around the cursor in the background so opening them is instant
+func (p PostsPage) prefetchComments() tea.Cmd {
+	posts, cursor := p.posts.Posts, p.list.Index()
+	return func() tea.Msg {
+		p.redditClient.PrefetchComments(posts, cursor)
+		return nil
+	}
+}
+
+// Name of what the page is showing, used to describe errors
+func (p PostsPage) subject() string {
+	switch p.kind {
+	case model.HomePosts:
+		return defaultHeaderTitle
+	case model.UserPosts:
+		return utils.NormalizeUser(p.User)
+	default:
+		if len(p.Subreddit) == 0 {
+			return defaultHeaderTitle
+		}
+
+		return utils.NormalizeSubreddit(p.Subreddit)
+	}
+}
+
+// Ask the user to confirm they are over 18, then load the posts again with retry
+func (p PostsPage) confirmOver18(subject string, retry tea.Msg) tea.Msg {
+	onConfirm := func() tea.Msg {
+		p.redditClient.ConfirmOver18()
+		return retry
+	}
+
+	return messages.ShowConfirmModalMsg{Message: fmt.Sprintf(over18Text, subject), OnConfirm: onConfirm}
+}
+
+func showPostsError(err error, subject string) tea.Msg {
+	slog.Error(postsErrorText, "error", err)
+	return messages.ShowErrorModalMsg{ErrorMsg: messages.DescribeError(err, subject, postsErrorText)}
+}
+
+// Reload the current page of posts using a different sort
+func (p *PostsPage) loadSorted(sort model.PostSort) tea.Cmd {
+	switch p.kind {
+	case model.HomePosts:
+		return p.loadHome()
+	case model.SearchPosts:
+		return p.loadSearch(p.query, p.Subreddit, sort)
+	case model.UserPosts:
+		return p.loadUser(p.User, sort)
+	default:
+		return p.loadSubreddit(p.Subreddit, sort)
+	}
+}
+
+// Search within the subreddit being viewed, or repeat the current search
+func (p PostsPage) showSearchModal() tea.Cmd {
+	switch p.kind {
+	case model.HomePosts, model.UserPosts:
+		return messages.ShowSearchModal("", "")
+	case model.SearchPosts:
+		return messages.ShowSearchModal(p.query, p.Subreddit)
+	default:
+		return messages.ShowSearchModal("", p.Subreddit)
+	}
+}
+
 func (p *PostsPage) updatePosts(posts model.Posts) {
 	p.posts = posts
 
-	if posts.IsHome {
+	switch posts.Kind() {
+	case model.HomePosts:
 		p.header.SetContent(defaultHeaderTitle, defaultHeaderDescription)
-	} else {
-		p.header.SetContent(posts.Subreddit, posts.Description)
+	case model.SearchPosts:
+		title := defaultHeaderTitle
+		if len(posts.Subreddit) > 0 {
+			title = posts.Subreddit
+		}
+
+		p.header.SetContent(title, fmt.Sprintf(searchDescription, posts.Query))
+		p.Subreddit = posts.Subreddit
+		p.query = posts.Query
+	case model.UserPosts:
+		if len(posts.Posts) == 0 {
+			p.header.SetUserContent(posts.User, userEmptyDescription)
+		} else {
+			p.header.SetUserContent(posts.User, userDescription)
+		}
+		p.User = posts.User
+	default:
+		if len(p.feed.Name) > 0 && posts.Subreddit == p.feed.Subreddit() {
+			p.header.SetFeedContent(p.feed.Name, utils.NormalizeSubreddit(posts.Subreddit))
+		} else {
+			p.header.SetContent(posts.Subreddit, posts.Description)
+		}
 		p.Subreddit = posts.Subreddit
 	}
+	p.header.SetSort(posts.Sort)
+	p.header.SetInstance(p.redditClient.ActiveInstance())
+	p.header.SetOffline(p.redditClient.IsOffline())
+	p.header.SetCached(posts)
 
+	p.firstPageSize = len(posts.Posts)
+	clear(p.hidden)
 	p.list.ResetSelected()
 
 	var listItems []list.Item
@@ -286,3 +608,277 @@ func (p *PostsPage) addPosts(posts model.Posts) {
 	// Need to set size again when content loads so padding and margins are correct
 	p.resizeComponents()
 }
+
+// Swap refreshed posts in for stale ones, keeping any pages loaded since and the selected post
+func (p *PostsPage) refreshPosts(posts model.Posts) {
+	var selected string
+	if index := p.list.Index(); index >= 0 && index < len(p.posts.Posts) {
+		selected = p.posts.Posts[index].CommentsUrl
+	}
+
+	// Posts after the first page were loaded from the server, so they are already fresh
+	morePosts := p.posts.Posts[min(p.firstPageSize, len(p.posts.Posts)):]
+	if len(morePosts) > 0 {
+		posts.After = p.posts.After
+	}
+
+	var (
+		uniqueTitles  = make(map[string]bool)
+		refreshed     []model.Post
+		firstPageSize int
+	)
+
+	for i, post := range slices.Concat(posts.Posts, morePosts) {
+		if uniqueTitles[post.PostTitle] {
+			continue
+		}
+
+		refreshed = append(refreshed, post)
+		uniqueTitles[post.PostTitle] = true
+		if i < len(posts.Posts) {
+			firstPageSize++
+		}
+	}
+
+	posts.Posts = refreshed
+	p.posts = posts
+	p.firstPageSize = firstPageSize
+	p.header.SetCached(posts)
+	p.header.SetInstance(p.redditClient.ActiveInstance())
+	p.header.SetOffline(p.redditClient.IsOffline())
+
+	var listItems []list.Item
+	cursor := min(p.list.Index(), len(refreshed)-1)
+	for i, post := range refreshed {
+		listItems = append(listItems, post)
+		if len(selected) > 0 && post.CommentsUrl == selected {
+			cursor = i
+		}
+	}
+
+	p.list.SetItems(listItems)
+	p.list.Select(max(cursor, 0))
+	p.resizeComponents()
+}
+
+func (p PostsPage) selectedPost() (model.Post, bool) {
+	post, ok := p.list.SelectedItem().(model.Post)
+	return post, ok
+}
+
+// Post that voting, saving and hiding apply to, or a command explaining why there is none
+func (p PostsPage) selectedAction(action string) (model.Post, tea.Cmd) {
+	if !p.redditClient.IsLoggedIn() {
+		return model.Post{}, messages.ShowErrorModal(messages.DescribeActionError(auth.ErrNotLoggedIn, action))
+	}
+
+	post, ok := p.selectedPost()
+	if !ok || len(post.Fullname()) == 0 {
+		return model.Post{}, messages.ShowErrorModal(cannotVoteText)
+	}
+
+	return post, nil
+}
+
+// Vote on the selected post right away, rolling back if reddit rejects the vote
+func (p *PostsPage) vote(vote int) tea.Cmd {
+	post, cmd := p.selectedAction("vote")
+	if cmd != nil {
+		return cmd
+	}
+
+	updated := post.WithVote(model.ToggleVote(post.Vote, vote))
+	p.replacePost(updated)
+
+	return func() tea.Msg {
+		if err := p.redditClient.Vote(context.Background(), post.Fullname(), updated.Vote); err != nil {
+			slog.Error("Could not vote", "post", post.Fullname(), "error", err)
+			return messages.RollbackPost(post, messages.DescribeActionError(err, "vote"))
+		}
+
+		return nil
+	}
+}
+
+func (p *PostsPage) toggleSave() tea.Cmd {
+	post, cmd := p.selectedAction("save")
+	if cmd != nil {
+		return cmd
+	}
+
+	updated := post
+	updated.Saved = !post.Saved
+	p.replacePost(updated)
+
+	return func() tea.Msg {
+		if err := p.redditClient.Save(context.Background(), post.Fullname(), updated.Saved); err != nil {
+			slog.Error("Could not save", "post", post.Fullname(), "error", err)
+			return messages.RollbackPost(post, messages.DescribeActionError(err, "save"))
+		}
+
+		return nil
+	}
+}
+
+func (p *PostsPage) hide() tea.Cmd {
+	post, cmd := p.selectedAction("hide posts")
+	if cmd != nil {
+		return cmd
+	}
+
+	p.removePost(post.Fullname())
+
+	return func() tea.Msg {
+		if err := p.redditClient.Hide(context.Background(), post.Fullname(), true); err != nil {
+			slog.Error("Could not hide", "post", post.Fullname(), "error", err)
+			return messages.RollbackHide(post.Fullname(), messages.DescribeActionError(err, "hide posts"))
+		}
+
+		return nil
+	}
+}
+
+// Open the user's editor to write a new post for the subreddit being viewed. The post is submitted after
+// the editor exits and the user confirms
+func (p *PostsPage) submit() tea.Cmd {
+	if !p.redditClient.IsLoggedIn() {
+		return messages.ShowErrorModal(messages.DescribeActionError(auth.ErrNotLoggedIn, "submit posts"))
+	} else if p.kind != model.SubredditPosts || len(p.Subreddit) == 0 || strings.Contains(p.Subreddit, "+") {
+		// Feeds combine several subreddits
+		return messages.ShowErrorModal(submitSubredditText)
+	}
+
+	subreddit := utils.NormalizeSubreddit(p.Subreddit)
+	path, err := utils.GetDraftPath("submit-" + strings.ToLower(strings.TrimPrefix(subreddit, "r/")))
+	if err == nil {
+		err = utils.PrepareDraft(path, utils.SubmissionTemplate(subreddit))
+	}
+	if err != nil {
+		slog.Error(draftErrorText, "error", err)
+		return messages.ShowErrorModal(draftErrorText)
+	}
+
+	return tea.ExecProcess(utils.EditorCommand(path), func(err error) tea.Msg {
+		if err != nil {
+			slog.Error(draftErrorText, "error", err)
+			return messages.ShowErrorModalMsg{ErrorMsg: draftErrorText}
+		}
+
+		draft, err := utils.ReadDraft(path)
+		if err != nil {
+			slog.Error(draftErrorText, "error", err)
+			return messages.ShowErrorModalMsg{ErrorMsg: draftErrorText}
+		}
+
+		submission := utils.ParseSubmission(draft)
+		if len(submission.Title) == 0 {
+			if len(submission.Url) == 0 && len(submission.Text) == 0 {
+				// Nothing was written, the post was cancelled
+				utils.DiscardDraft(path)
+				return nil
+			}
+
+			return messages.ShowErrorModalMsg{ErrorMsg: missingTitleText}
+		}
+
+		kind := "text"
+		if len(submission.Url) > 0 {
+			kind = "link"
+		}
+
+		return messages.ShowConfirmModalMsg{
+			Message:   fmt.Sprintf(confirmSubmitText, kind, submission.Title, subreddit),
+			OnConfirm: p.postSubmission(subreddit, submission, path),
+		}
+	})
+}
+
+// Submit the post and open it once it is up
+func (p PostsPage) postSubmission(subreddit string, submission utils.Submission, draftPath string) tea.Cmd {
+	return func() tea.Msg {
+		commentsUrl, err := p.redditClient.Submit(context.Background(), subreddit, submission.Title, submission.Url, submission.Text)
+		if err != nil {
+			slog.Error("Could not submit post", "subreddit", subreddit, "error", err)
+			return messages.ShowErrorModalMsg{ErrorMsg: fmt.Sprintf(draftKeptText, messages.DescribeActionError(err, "submit the post"))}
+		}
+
+		if err := utils.DiscardDraft(draftPath); err != nil {
+			slog.Warn("Could not remove draft", "path", draftPath, "error", err)
+		}
+
+		return messages.LoadCommentsMsg(commentsUrl)
+	}
+}
+
+// Reveal the title of the selected post when it is masked as nsfw or a spoiler. Posts are matched by
+// their comments url since not every instance gives posts a fullname
+func (p *PostsPage) revealTitle() {
+	post, ok := p.selectedPost()
+	if !ok || !post.Masked {
+		return
+	}
+
+	post.Masked = false
+	for i, current := range p.posts.Posts {
+		if current.CommentsUrl == post.CommentsUrl {
+			p.posts.Posts[i] = post
+		}
+	}
+
+	p.list.SetItem(p.list.Index(), post)
+}
+
+// Swap in a post after voting or saving, matching it by its fullname
+func (p *PostsPage) replacePost(post model.Post) {
+	fullname := post.Fullname()
+	for i, current := range p.posts.Posts {
+		if current.Fullname() == fullname {
+			p.posts.Posts[i] = post
+		}
+	}
+
+	for i, item := range p.list.Items() {
+		if current, ok := item.(model.Post); ok && current.Fullname() == fullname {
+			p.list.SetItem(i, post)
+		}
+	}
+}
+
+func (p *PostsPage) removePost(fullname string) {
+	listIndex := slices.IndexFunc(p.list.Items(), func(item list.Item) bool {
+		post, ok := item.(model.Post)
+		return ok && post.Fullname() == fullname
+	})
+	index := slices.IndexFunc(p.posts.Posts, func(post model.Post) bool { return post.Fullname() == fullname })
+	if listIndex < 0 || index < 0 {
+		return
+	}
+
+	p.hidden[fullname] = hiddenPost{p.posts.Posts[index], listIndex, index}
+	p.list.RemoveItem(listIndex)
+	p.posts.Posts = slices.Delete(p.posts.Posts, index, index+1)
+	if index < p.firstPageSize {
+		p.firstPageSize--
+	}
+}
+
+// Put a hidden post back where it was
+func (p *PostsPage) unhidePost(fullname string) {
+	hidden, ok := p.hidden[fullname]
+	if !ok {
+		return
+	}
+
+	delete(p.hidden, fullname)
+	index := min(hidden.index, len(p.posts.Posts))
+	p.posts.Posts = slices.Insert(p.posts.Posts, index, hidden.post)
+	p.list.InsertItem(min(hidden.listIndex, len(p.list.Items())), hidden.post)
+	if index < p.firstPageSize {
+		p.firstPageSize++
+	}
+}
+
+// Refreshed posts only replace stale ones when the page is still showing the same listing
+func isSameListing(a, b model.Posts) bool {
+	return a.Kind() == b.Kind() && a.Subreddit == b.Subreddit && a.Query == b.Query && a.User == b.User && a.Sort == b.Sort
+}
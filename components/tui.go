@@ -1,6 +1,7 @@
 package components
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"reddittui/client"
@@ -9,6 +10,7 @@ import (
 	"reddittui/components/modal"
 	"reddittui/components/posts"
 	"reddittui/config"
+	"reddittui/model"
 	"reddittui/utils"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -24,12 +26,16 @@ const (
 	HomePage pageType = iota
 	SubredditPage
 	CommentsPage
+	SearchPage
+	UserPage
 )
 
 type RedditTui struct {
 	redditClient  client.RedditClient
 	homePage      posts.PostsPage
 	subredditPage posts.PostsPage
+	searchPage    posts.PostsPage
+	userPage      posts.PostsPage
 	commentsPage  comments.CommentsPage
 	modalManager  modal.ModalManager
 	popup         bool
@@ -40,29 +46,50 @@ type RedditTui struct {
 	initCmd       tea.Cmd
 }
 
-func NewRedditTui(configuration config.Config, subreddit, post string) RedditTui {
+func NewRedditTui(configuration config.Config, subreddit, post, user string) RedditTui {
 	redditClient := client.NewRedditClient(configuration)
 
-	homePage := posts.NewPostsPage(redditClient, true)
-	subredditPage := posts.NewPostsPage(redditClient, false)
+	homePage := posts.NewPostsPage(redditClient, model.HomePosts)
+	subredditPage := posts.NewPostsPage(redditClient, model.SubredditPosts)
+	searchPage := posts.NewPostsPage(redditClient, model.SearchPosts)
+	userPage := posts.NewPostsPage(redditClient, model.UserPosts)
 	commentsPage := comments.NewCommentsPage(redditClient)
 
-	modalManager := modal.NewModalManager()
+	modalManager := modal.NewModalManager(getFeeds(configuration))
 
 	return RedditTui{
 		redditClient:  redditClient,
 		homePage:      homePage,
 		subredditPage: subredditPage,
+		searchPage:    searchPage,
+		userPage:      userPage,
 		commentsPage:  commentsPage,
 		modalManager:  modalManager,
 		initializing:  true,
-		initCmd:       getInitCmd(redditClient.BaseUrl, subreddit, post),
+		initCmd:       getInitCmd(redditClient.BaseUrl, subreddit, post, user),
 	}
 }
 
-func getInitCmd(baseUrl, subreddit, post string) tea.Cmd {
+func getFeeds(configuration config.Config) []model.Feed {
+	var feeds []model.Feed
+	for _, feedConfig := range configuration.Feeds {
+		feed := model.NewFeed(feedConfig.Name, feedConfig.Subreddits, feedConfig.Sort, feedConfig.Timeframe)
+		if len(feed.Name) == 0 || len(feed.Subreddits) == 0 {
+			slog.Warn("Skipping feed without a name or subreddits", "name", feedConfig.Name)
+			continue
+		}
+
+		feeds = append(feeds, feed)
+	}
+
+	return feeds
+}
+
+func getInitCmd(baseUrl, subreddit, post, user string) tea.Cmd {
 	if len(subreddit) != 0 {
 		return messages.LoadSubreddit(subreddit)
+	} else if len(user) != 0 {
+		return messages.LoadUser(user)
 	} else if len(post) != 0 {
 		url, err := client.GetPostUrl(baseUrl, post)
 		if err != nil {
@@ -76,7 +103,24 @@ func getInitCmd(baseUrl, subreddit, post string) tea.Cmd {
 }
 
 func (r RedditTui) Init() tea.Cmd {
-	return messages.LoadHome
+	return tea.Batch(messages.LoadHome, r.loadSubscriptions())
+}
+
+// List the logged in user's subscriptions in the subreddit search modal
+func (r RedditTui) loadSubscriptions() tea.Cmd {
+	if !r.redditClient.IsLoggedIn() {
+		return nil
+	}
+
+	return func() tea.Msg {
+		subscriptions, err := r.redditClient.GetSubscriptions(context.Background())
+		if err != nil {
+			slog.Warn("Could not load subscriptions", "error", err)
+			return nil
+		}
+
+		return messages.UpdateSubscriptionsMsg(subscriptions)
+	}
 }
 
 func (r RedditTui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -91,12 +135,18 @@ func (r RedditTui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			slog.Error("Error during initialization")
 			if r.loadingPage == HomePage {
 				errorMsg := "Could not initialize reddittui. Check the logfile for details."
+				if r.redditClient.IsOffline() {
+					errorMsg = "Could not initialize reddittui. The home page has not been cached for offline use."
+				}
+
 				return r, messages.ShowErrorModalWithCallback(errorMsg, tea.Quit)
 			}
 
 			var errorMsg string
 			if r.loadingPage == SubredditPage {
 				errorMsg = "Error loading subreddit. Returning to home page..."
+			} else if r.loadingPage == UserPage {
+				errorMsg = "Error loading user. Returning to home page..."
 			} else {
 				errorMsg = "Error loading post. Returning to home page..."
 			}
@@ -104,6 +154,14 @@ func (r RedditTui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return r, messages.ShowErrorModalWithCallback(errorMsg, messages.LoadHome)
 		}
 
+	case messages.ShowConfirmModalMsg:
+		// There is no page to return to if the user declines while the first page is loading
+		if r.initializing && msg.OnCancel == nil && r.loadingPage != HomePage {
+			msg.OnCancel = messages.LoadHome
+			r.modalManager, cmd = r.modalManager.Update(msg)
+			return r, cmd
+		}
+
 	case messages.CleanCacheMsg:
 		r.redditClient.CleanCache()
 		return r, nil
@@ -116,6 +174,18 @@ func (r RedditTui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmd = r.completeLoading()
 		return r, cmd
 
+	case messages.CancelLoadingMsg:
+		// Nothing has been loaded yet, so there is no page to go back to
+		if r.initializing {
+			return r, tea.Quit
+		}
+
+		// The pages cancel their in flight requests below, the previous page is still active
+		r.popup = false
+		r.focusActivePage()
+		cmd = r.modalManager.Blur()
+		cmds = append(cmds, cmd)
+
 	case messages.ExitModalMsg:
 		r.popup = false
 		r.focusActivePage()
@@ -146,6 +216,30 @@ func (r RedditTui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmd = r.modalManager.SetLoading(loadingMsg)
 		cmds = append(cmds, cmd)
 
+	case messages.LoadFeedMsg:
+		r.focusModal()
+		r.loadingPage = SubredditPage
+
+		cmd = r.modalManager.SetLoading(fmt.Sprintf("loading %s...", msg.Name))
+		cmds = append(cmds, cmd)
+
+	case messages.LoadUserMsg:
+		user := string(msg)
+		r.focusModal()
+		r.loadingPage = UserPage
+
+		loadingMsg := fmt.Sprintf("loading %s...", utils.NormalizeUser(user))
+		cmd = r.modalManager.SetLoading(loadingMsg)
+		cmds = append(cmds, cmd)
+
+	case messages.LoadSearchMsg:
+		r.focusModal()
+		r.loadingPage = SearchPage
+
+		loadingMsg := fmt.Sprintf("searching for \"%s\"...", msg.Query)
+		cmd = r.modalManager.SetLoading(loadingMsg)
+		cmds = append(cmds, cmd)
+
 	case messages.LoadMorePostsMsg:
 		r.focusModal()
 		r.loadingPage = r.page
@@ -153,6 +247,13 @@ func (r RedditTui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmd = r.modalManager.SetLoading("loading posts...")
 		cmds = append(cmds, cmd)
 
+	case messages.SortPostsMsg:
+		r.focusModal()
+		r.loadingPage = r.page
+
+		cmd = r.modalManager.SetLoading(fmt.Sprintf("loading %s posts...", msg.Sort.String()))
+		cmds = append(cmds, cmd)
+
 	case messages.LoadCommentsMsg:
 		r.focusModal()
 		r.loadingPage = CommentsPage
@@ -160,6 +261,21 @@ func (r RedditTui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmd = r.modalManager.SetLoading("loading comments...")
 		cmds = append(cmds, cmd)
 
+	case messages.SortCommentsMsg:
+		r.focusModal()
+		r.loadingPage = CommentsPage
+
+		loadingMsg := fmt.Sprintf("loading %s comments...", model.CommentSortDescription(string(msg)))
+		cmd = r.modalManager.SetLoading(loadingMsg)
+		cmds = append(cmds, cmd)
+
+	case messages.LoadMoreCommentsMsg:
+		r.focusModal()
+		r.loadingPage = CommentsPage
+
+		cmd = r.modalManager.SetLoading("loading more comments...")
+		cmds = append(cmds, cmd)
+
 	case messages.OpenUrlMsg:
 		url := string(msg)
 		if err := utils.OpenUrl(url); err != nil {
@@ -171,6 +287,8 @@ func (r RedditTui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		r.homePage.SetSize(msg.Width, msg.Height)
 		r.subredditPage.SetSize(msg.Width, msg.Height)
+		r.searchPage.SetSize(msg.Width, msg.Height)
+		r.userPage.SetSize(msg.Width, msg.Height)
 		r.commentsPage.SetSize(msg.Width, msg.Height)
 		r.modalManager.SetSize(msg.Width, msg.Height)
 
@@ -190,6 +308,12 @@ func (r RedditTui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	r.subredditPage, cmd = r.subredditPage.Update(msg)
 	cmds = append(cmds, cmd)
 
+	r.searchPage, cmd = r.searchPage.Update(msg)
+	cmds = append(cmds, cmd)
+
+	r.userPage, cmd = r.userPage.Update(msg)
+	cmds = append(cmds, cmd)
+
 	r.commentsPage, cmd = r.commentsPage.Update(msg)
 	cmds = append(cmds, cmd)
 
@@ -203,6 +327,10 @@ func (r RedditTui) View() string {
 			return r.modalManager.View(r.homePage)
 		case SubredditPage:
 			return r.modalManager.View(r.subredditPage)
+		case SearchPage:
+			return r.modalManager.View(r.searchPage)
+		case UserPage:
+			return r.modalManager.View(r.userPage)
 		case CommentsPage:
 			return r.modalManager.View(r.commentsPage)
 		}
@@ -213,6 +341,10 @@ func (r RedditTui) View() string {
 		return r.homePage.View()
 	case SubredditPage:
 		return r.subredditPage.View()
+	case SearchPage:
+		return r.searchPage.View()
+	case UserPage:
+		return r.userPage.View()
 	case CommentsPage:
 		return r.commentsPage.View()
 	}
@@ -223,10 +355,10 @@ func (r RedditTui) View() string {
 func (r *RedditTui) goBack() {
 	switch r.page {
 	case CommentsPage:
-		if r.prevPage == HomePage {
+		if r.prevPage == CommentsPage {
 			r.setPage(HomePage)
 		} else {
-			r.setPage(SubredditPage)
+			r.setPage(r.prevPage)
 		}
 	default:
 		r.setPage(HomePage)
@@ -236,7 +368,10 @@ func (r *RedditTui) goBack() {
 }
 
 func (r *RedditTui) setPage(page pageType) {
-	r.page, r.prevPage = page, r.page
+	// Reloading the current page, e.g. after sorting, should not lose track of the previous page
+	if page != r.page {
+		r.page, r.prevPage = page, r.page
+	}
 }
 
 func (r *RedditTui) completeLoading() tea.Cmd {
@@ -259,22 +394,28 @@ func (r *RedditTui) focusModal() {
 	r.popup = true
 	r.homePage.Blur()
 	r.subredditPage.Blur()
+	r.searchPage.Blur()
+	r.userPage.Blur()
 	r.commentsPage.Blur()
 }
 
 func (r *RedditTui) focusActivePage() {
+	r.homePage.Blur()
+	r.subredditPage.Blur()
+	r.searchPage.Blur()
+	r.userPage.Blur()
+	r.commentsPage.Blur()
+
 	switch r.page {
 	case HomePage:
 		r.homePage.Focus()
-		r.subredditPage.Blur()
-		r.commentsPage.Blur()
 	case SubredditPage:
-		r.homePage.Blur()
 		r.subredditPage.Focus()
-		r.commentsPage.Blur()
+	case SearchPage:
+		r.searchPage.Focus()
+	case UserPage:
+		r.userPage.Focus()
 	case CommentsPage:
-		r.homePage.Blur()
-		r.subredditPage.Blur()
 		r.commentsPage.Focus()
 	}
 }
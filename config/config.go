@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"reddittui/model"
 	"reddittui/utils"
 
 	"github.com/BurntSushi/toml"
@@ -13,34 +14,87 @@ const (
 	configFilename    = "reddittui.toml"
 	defaultDomainName = "old.reddit.com"
 	defaultServerType = "old"
+	defaultCooldown   = 300
+	defaultRetries    = 2
+	defaultRetryDelay = 500
+
+	defaultPrefetchDepth       = 3
+	defaultPrefetchConcurrency = 2
+	defaultMaxStale            = 24 * 60 * 60
+
+	defaultSyncPages   = 2
+	defaultSyncThreads = 10
+	defaultSyncTtl     = 7 * 24 * 60 * 60
 )
 
 type Config struct {
-	Core   CoreConfig   `toml:"core"`
-	Filter FilterConfig `toml:"filter"`
-	Client ClientConfig `toml:"client"`
-	Server ServerConfig `toml:"server"`
+	Core     CoreConfig     `toml:"core"`
+	Filter   FilterConfig   `toml:"filter"`
+	Client   ClientConfig   `toml:"client"`
+	Server   ServerConfig   `toml:"server"`
+	Comments CommentsConfig `toml:"comments"`
+	Feeds    []FeedConfig   `toml:"feeds"`
+	Sync     SyncConfig     `toml:"sync"`
 }
 
 type CoreConfig struct {
 	BypassCache   bool
 	LogLevel      string
-	ClientTimeout int // Legacy
+	ClientTimeout int  // Legacy
+	Offline       bool `toml:"-"` // Set with the --offline flag
 }
 
 type FilterConfig struct {
 	Keywords   []string
 	Subreddits []string
+	Nsfw       string
+	Spoilers   string
 }
 
 type ClientConfig struct {
-	TimeoutSeconds  int
-	CacheTtlSeconds int
+	TimeoutSeconds      int
+	CacheTtlSeconds     int
+	MaxRetries          int
+	RetryDelayMillis    int
+	MaxStaleSeconds     int
+	Prefetch            bool
+	PrefetchDepth       int
+	PrefetchConcurrency int
+	Proxy               string
+	Proxies             map[string]string // Proxies for individual servers, overriding proxy
+	NoProxy             []string
+	UserAgent           string
+	Headers             map[string]string
+	Cookies             bool // Keep cookies between runs
 }
 
 type ServerConfig struct {
-	Domain string
-	Type   string
+	Domain          string
+	Type            string
+	Instances       []string
+	CooldownSeconds int
+}
+
+type CommentsConfig struct {
+	DefaultSort    string
+	SubredditSorts map[string]string
+}
+
+type FeedConfig struct {
+	Name       string
+	Subreddits []string
+	Sort       string
+	Timeframe  string
+}
+
+// Listings downloaded by the sync command for offline reading
+type SyncConfig struct {
+	Home       bool
+	Subreddits []string
+	Feeds      []string // Names of configured feeds
+	Pages      int
+	Threads    int
+	TtlSeconds int
 }
 
 func NewConfig() Config {
@@ -50,9 +104,28 @@ func NewConfig() Config {
 			LogLevel:      "Warn",
 			ClientTimeout: 10,
 		},
+		Filter: FilterConfig{
+			Nsfw:     model.TagPosts,
+			Spoilers: model.TagPosts,
+		},
+		Client: ClientConfig{
+			MaxRetries:          defaultRetries,
+			RetryDelayMillis:    defaultRetryDelay,
+			MaxStaleSeconds:     defaultMaxStale,
+			Prefetch:            true,
+			PrefetchDepth:       defaultPrefetchDepth,
+			PrefetchConcurrency: defaultPrefetchConcurrency,
+		},
 		Server: ServerConfig{
-			Domain: defaultDomainName,
-			Type:   defaultServerType,
+			Domain:          defaultDomainName,
+			Type:            defaultServerType,
+			CooldownSeconds: defaultCooldown,
+		},
+		Sync: SyncConfig{
+			Home:       true,
+			Pages:      defaultSyncPages,
+			Threads:    defaultSyncThreads,
+			TtlSeconds: defaultSyncTtl,
 		},
 	}
 }
@@ -118,6 +191,14 @@ func mergeConfig(left, right Config, meta toml.MetaData) Config {
 		left.Filter.Subreddits = right.Filter.Subreddits
 	}
 
+	if meta.IsDefined("filter", "nsfw") {
+		left.Filter.Nsfw = right.Filter.Nsfw
+	}
+
+	if meta.IsDefined("filter", "spoilers") {
+		left.Filter.Spoilers = right.Filter.Spoilers
+	}
+
 	if meta.IsDefined("client", "timeoutSeconds") {
 		left.Client.TimeoutSeconds = right.Client.TimeoutSeconds
 	}
@@ -126,6 +207,54 @@ func mergeConfig(left, right Config, meta toml.MetaData) Config {
 		left.Client.CacheTtlSeconds = right.Client.CacheTtlSeconds
 	}
 
+	if meta.IsDefined("client", "maxRetries") {
+		left.Client.MaxRetries = right.Client.MaxRetries
+	}
+
+	if meta.IsDefined("client", "retryDelayMillis") {
+		left.Client.RetryDelayMillis = right.Client.RetryDelayMillis
+	}
+
+	if meta.IsDefined("client", "maxStaleSeconds") {
+		left.Client.MaxStaleSeconds = right.Client.MaxStaleSeconds
+	}
+
+	if meta.IsDefined("client", "prefetch") {
+		left.Client.Prefetch = right.Client.Prefetch
+	}
+
+	if meta.IsDefined("client", "prefetchDepth") {
+		left.Client.PrefetchDepth = right.Client.PrefetchDepth
+	}
+
+	if meta.IsDefined("client", "prefetchConcurrency") {
+		left.Client.PrefetchConcurrency = right.Client.PrefetchConcurrency
+	}
+
+	if meta.IsDefined("client", "proxy") {
+		left.Client.Proxy = right.Client.Proxy
+	}
+
+	if meta.IsDefined("client", "proxies") {
+		left.Client.Proxies = right.Client.Proxies
+	}
+
+	if meta.IsDefined("client", "noProxy") {
+		left.Client.NoProxy = right.Client.NoProxy
+	}
+
+	if meta.IsDefined("client", "userAgent") {
+		left.Client.UserAgent = right.Client.UserAgent
+	}
+
+	if meta.IsDefined("client", "headers") {
+		left.Client.Headers = right.Client.Headers
+	}
+
+	if meta.IsDefined("client", "cookies") {
+		left.Client.Cookies = right.Client.Cookies
+	}
+
 	if meta.IsDefined("server", "domain") {
 		left.Server.Domain = right.Server.Domain
 	}
@@ -134,6 +263,50 @@ func mergeConfig(left, right Config, meta toml.MetaData) Config {
 		left.Server.Type = right.Server.Type
 	}
 
+	if meta.IsDefined("server", "instances") {
+		left.Server.Instances = right.Server.Instances
+	}
+
+	if meta.IsDefined("server", "cooldownSeconds") {
+		left.Server.CooldownSeconds = right.Server.CooldownSeconds
+	}
+
+	if meta.IsDefined("comments", "defaultSort") {
+		left.Comments.DefaultSort = right.Comments.DefaultSort
+	}
+
+	if meta.IsDefined("comments", "subredditSorts") {
+		left.Comments.SubredditSorts = right.Comments.SubredditSorts
+	}
+
+	if meta.IsDefined("feeds") {
+		left.Feeds = right.Feeds
+	}
+
+	if meta.IsDefined("sync", "home") {
+		left.Sync.Home = right.Sync.Home
+	}
+
+	if meta.IsDefined("sync", "subreddits") {
+		left.Sync.Subreddits = right.Sync.Subreddits
+	}
+
+	if meta.IsDefined("sync", "feeds") {
+		left.Sync.Feeds = right.Sync.Feeds
+	}
+
+	if meta.IsDefined("sync", "pages") {
+		left.Sync.Pages = right.Sync.Pages
+	}
+
+	if meta.IsDefined("sync", "threads") {
+		left.Sync.Threads = right.Sync.Threads
+	}
+
+	if meta.IsDefined("sync", "ttlSeconds") {
+		left.Sync.TtlSeconds = right.Sync.TtlSeconds
+	}
+
 	return left
 }
 
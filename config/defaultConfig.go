@@ -13,12 +13,68 @@ const defaultConfiguration = `
 #[filter]
 #keywords = ["drama"]
 #subreddits = ["news", "politics"]
+# Show, tag, mask or hide posts marked nsfw or as spoilers, one of "show", "tag", "mask" or "hide".
+# Masked posts are tagged and their titles are hidden until revealed with v
+#nsfw = "tag"
+#spoilers = "tag"
 
 #[client]
 #timeoutSeconds = 10
 #cacheTtlSeconds = 3600
+# Expired posts and comments are shown while they refresh, and kept on disk for this long
+#maxStaleSeconds = 86400
+# Retry rate limited reads, server errors and timeouts, doubling the delay after each attempt. Writes such
+# as votes and replies are only sent once
+#maxRetries = 2
+#retryDelayMillis = 500
+# Load comments for the posts around the cursor in the background. Turn off on metered connections
+#prefetch = true
+#prefetchDepth = 3
+#prefetchConcurrency = 2
+# Send requests through an http, https or socks5 proxy. Defaults to the HTTP_PROXY and HTTPS_PROXY environment variables
+#proxy = "http://proxy.example.com:8080"
+# Hosts that are never proxied, including their subdomains
+#noProxy = ["localhost", "internal.example.com"]
+# Defaults to a desktop firefox user agent
+#userAgent = "Mozilla/5.0 (X11; Linux x86_64; rv:134.0) Gecko/20100101 Firefox/134.0"
+# Keep cookies set by the server, such as redlib preferences, between runs
+#cookies = false
+
+# Extra headers sent with every request
+#[client.headers]
+#"Accept-Language" = "en-US"
+
+# Proxies for individual servers, overriding proxy. Use "direct" to skip the proxy for a server
+#[client.proxies]
+#"redlib.example.com" = "socks5h://127.0.0.1:9050"
 
 #[server]
 #domain = "old.reddit.com"
-#type = "old"
+#type = "old" # one of "old", "redlib" or "json"
+# Ordered list of servers to fail over between, replaces domain when set
+#instances = ["redlib.example.com", "redlib.example.org"]
+# Seconds to skip a server after it fails
+#cooldownSeconds = 300
+
+#[comments]
+#defaultSort = "best" # one of "best", "top", "new", "controversial", "old" or "qa"
+
+#[comments.subredditSorts]
+#askhistorians = "top"
+
+# Named feeds of subreddits, loaded together from the subreddit search modal
+#[[feeds]]
+#name = "languages"
+#subreddits = ["golang", "rust", "zig"]
+#sort = "top" # one of "hot", "new", "top", "rising" or "controversial"
+#timeframe = "week" # one of "hour", "day", "week", "month", "year" or "all", for top and controversial sorts
+
+# Listings downloaded by "reddittui sync" for offline reading
+#[sync]
+#home = true
+#subreddits = ["golang", "linux"]
+#feeds = ["languages"] # names of feeds defined above
+#pages = 2
+#threads = 10 # comment threads downloaded for the first posts of each listing
+#ttlSeconds = 604800
 `
@@ -22,7 +22,7 @@ func TestStartup(t *testing.T) {
 	t.Logf("Testing startup...")
 	configuration := getTestConfig()
 
-	tui := components.NewRedditTui(configuration, "", "")
+	tui := components.NewRedditTui(configuration, "", "", "")
 	tm := teatest.NewTestModel(t, tui, teatest.WithInitialTermSize(300, 100))
 
 	t.Logf("\tVerify the loading screen shows on startup...")
@@ -36,7 +36,7 @@ func TestSwitchSubreddit(t *testing.T) {
 	t.Logf("Testing switching subreddit...")
 	configuration := getTestConfig()
 
-	tui := components.NewRedditTui(configuration, "", "")
+	tui := components.NewRedditTui(configuration, "", "", "")
 	tm := teatest.NewTestModel(t, tui, teatest.WithInitialTermSize(300, 100))
 
 	t.Logf("\tVerify home page loads...")
@@ -58,7 +58,7 @@ func TestReturnToHomePage(t *testing.T) {
 	t.Logf("Testing returning to the home page after switching subreddits...")
 	configuration := getTestConfig()
 
-	tui := components.NewRedditTui(configuration, "", "")
+	tui := components.NewRedditTui(configuration, "", "", "")
 	tm := teatest.NewTestModel(t, tui, teatest.WithInitialTermSize(300, 100))
 
 	t.Logf("\tVerify home page loads...")
@@ -85,7 +85,7 @@ func TestShowComments(t *testing.T) {
 	t.Logf("Testing show post comments...")
 	configuration := getTestConfig()
 
-	tui := components.NewRedditTui(configuration, "", "")
+	tui := components.NewRedditTui(configuration, "", "", "")
 	tm := teatest.NewTestModel(t, tui, teatest.WithInitialTermSize(300, 100))
 
 	t.Logf("\tVerify home page loads...")
@@ -112,7 +112,7 @@ func TestLoadInitialPostFromId(t *testing.T) {
 	configuration := getTestConfig()
 
 	postId := "1jgxswb"
-	tui := components.NewRedditTui(configuration, "", postId)
+	tui := components.NewRedditTui(configuration, "", postId, "")
 	tm := teatest.NewTestModel(t, tui, teatest.WithInitialTermSize(300, 100))
 
 	t.Logf("\tVerify comments header loads...")
@@ -125,7 +125,7 @@ func TestLoadInitialPostFromUrl(t *testing.T) {
 	configuration := getTestConfig()
 
 	postUrl := "https://old.reddit.com/r/dogs/comments/1jh0yne/dog_becoming_cuddlier_as_a_senior/"
-	tui := components.NewRedditTui(configuration, "", postUrl)
+	tui := components.NewRedditTui(configuration, "", postUrl, "")
 	tm := teatest.NewTestModel(t, tui, teatest.WithInitialTermSize(300, 100))
 
 	t.Logf("\tVerify comments header loads...")
@@ -137,7 +137,7 @@ func TestLoadInitialSubredditAndCanGoBack(t *testing.T) {
 	t.Logf("Testing loading subreddit...")
 	configuration := getTestConfig()
 
-	tui := components.NewRedditTui(configuration, "dogs", "")
+	tui := components.NewRedditTui(configuration, "dogs", "", "")
 	tm := teatest.NewTestModel(t, tui, teatest.WithInitialTermSize(300, 100))
 
 	t.Logf("\tVerify dog subreddit loads...")
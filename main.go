@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
+	"reddittui/client"
+	"reddittui/client/auth"
 	"reddittui/components"
 	"reddittui/config"
 	"reddittui/utils"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -17,6 +23,8 @@ const version = "v0.3.9"
 type CliArgs struct {
 	subreddit   string
 	postId      string
+	user        string
+	offline     bool
 	showVersion bool
 }
 
@@ -30,9 +38,22 @@ func main() {
 
 	defer logFile.Close()
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "sync":
+			os.Exit(runSync(configuration))
+		case "login":
+			os.Exit(runLogin(configuration))
+		case "logout":
+			os.Exit(runLogout(configuration))
+		}
+	}
+
 	var args CliArgs
 	flag.StringVar(&args.postId, "post", "", "Post id")
 	flag.StringVar(&args.subreddit, "subreddit", "", "Subreddit")
+	flag.StringVar(&args.user, "user", "", "User")
+	flag.BoolVar(&args.offline, "offline", false, "Only show cached posts and comments")
 	flag.BoolVar(&args.showVersion, "version", false, "Version")
 	flag.Parse()
 
@@ -41,7 +62,9 @@ func main() {
 		os.Exit(0)
 	}
 
-	reddit := components.NewRedditTui(configuration, args.subreddit, args.postId)
+	configuration.Core.Offline = args.offline
+
+	reddit := components.NewRedditTui(configuration, args.subreddit, args.postId, args.user)
 	p := tea.NewProgram(reddit, tea.WithAltScreen())
 
 	if _, err := p.Run(); err != nil {
@@ -49,3 +72,93 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// Download the listings in the sync section of the configuration into the cache, so they can be
+// browsed with --offline later. Returns the exit code
+func runSync(configuration config.Config) int {
+	if configuration.Core.BypassCache {
+		fmt.Fprintln(os.Stderr, "Cannot sync while the cache is bypassed, set bypassCache = false")
+		return 1
+	}
+
+	targets := client.GetSyncTargets(configuration)
+	if len(targets) == 0 {
+		fmt.Fprintln(os.Stderr, "Nothing to sync, add subreddits or feeds to the [sync] section of the configuration file")
+		return 1
+	}
+
+	// Synced posts and comments are cached with the sync ttl instead of the usual one
+	configuration.Client.CacheTtlSeconds = configuration.Sync.TtlSeconds
+	configuration.Client.Prefetch = false
+	redditClient := client.NewRedditClient(configuration)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var (
+		start    = time.Now()
+		seen     = make(map[string]bool)
+		failures int
+	)
+
+	for _, target := range targets {
+		result, err := redditClient.Sync(ctx, target, configuration.Sync.Pages, configuration.Sync.Threads, seen)
+		if errors.Is(err, context.Canceled) {
+			fmt.Fprintln(os.Stderr, "Sync cancelled")
+			return 1
+		} else if err != nil {
+			failures++
+			slog.Error("Could not sync", "target", target.Name, "error", err)
+			fmt.Fprintf(os.Stderr, "%s: %v\n", target.Name, err)
+			continue
+		}
+
+		fmt.Printf("%s: %d pages, %d posts, %d comment threads\n", target.Name, result.Pages, result.Posts, result.Threads)
+	}
+
+	fmt.Printf("Synced %d of %d listings in %s\n", len(targets)-failures, len(targets), time.Since(start).Round(time.Second))
+	if failures > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// Authorize reddittui to use the reddit account in the auth.toml credentials file. Returns the exit code
+func runLogin(configuration config.Config) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	openUrl := func(url string) error {
+		fmt.Printf("Opening %s to authorize reddittui, waiting for the redirect...\n", url)
+		if err := utils.OpenUrl(url); err != nil {
+			fmt.Println("Could not open the browser, open the url above manually")
+		}
+
+		return nil
+	}
+
+	err := client.Login(ctx, configuration, openUrl)
+	if errors.Is(err, auth.ErrNotLoggedIn) {
+		fmt.Fprintf(os.Stderr, "No credentials found, add your reddit app to %s in the configuration directory\n", auth.CredentialsFileName)
+		return 1
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not log in: %v\n", err)
+		return 1
+	}
+
+	fmt.Println("Logged in, the home page now shows your subscriptions")
+	return 0
+}
+
+// Forget the saved tokens. Returns the exit code
+func runLogout(configuration config.Config) int {
+	err := client.Logout(configuration)
+	if err != nil && !errors.Is(err, auth.ErrNotLoggedIn) {
+		fmt.Fprintf(os.Stderr, "Could not log out: %v\n", err)
+		return 1
+	}
+
+	fmt.Println("Logged out")
+	return 0
+}
@@ -2,28 +2,132 @@ package model
 
 import (
 	"fmt"
+	"reddittui/utils"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// Text shown in place of comments deleted by their author or removed by moderators
+const (
+	DeletedText = "[deleted]"
+	RemovedText = "[removed]"
+)
+
 type Comment struct {
-	Author    string `json:"author"`
-	Text      string `json:"text"`
-	Points    string `json:"points"`
-	Timestamp string `json:"timestamp"`
-	Depth     int    `json:"depth"`
+	Id        string    `json:"id"`
+	Author    string    `json:"author"`
+	Text      string    `json:"text"`
+	Points    string    `json:"points"`
+	Score     int       `json:"score"`
+	Timestamp string    `json:"timestamp"`
+	Created   time.Time `json:"created"`
+	Depth     int       `json:"depth"`
+	MoreIds   []string  `json:"moreIds,omitempty"`
+	MoreUrl   string    `json:"moreUrl,omitempty"`
+	MoreCount int       `json:"moreCount,omitempty"`
+	Vote      int       `json:"vote,omitempty"`
+	Saved     bool      `json:"saved,omitempty"`
+
+	// Placeholder for a deleted or removed comment, kept so its replies stay in the thread
+	Deleted bool `json:"deleted,omitempty"`
+
+	// Html of the comment's markdown, rendered again at the width it is shown at. Text holds the same
+	// comment rendered without wrapping
+	Html string `json:"html,omitempty"`
 }
 
 type Comments struct {
+	PostId        string    `json:"id"`
 	PostTitle     string    `json:"title"`
 	PostAuthor    string    `json:"author"`
 	Subreddit     string    `json:"subreddit"`
 	PostPoints    string    `json:"points"`
 	PostText      string    `json:"text"`
+	PostHtml      string    `json:"html,omitempty"`
 	PostUrl       string    `json:"url"`
 	PostTimestamp string    `json:"timestamp"`
+	Sort          string    `json:"sort"`
 	Expiry        time.Time `json:"expiry"`
+	Fetched       time.Time `json:"fetched"`
 	Comments      []Comment `json:"comments"`
+
+	// Set when the comments were served from an expired cache entry and should be refreshed
+	Stale bool `json:"-"`
+}
+
+// Placeholder for replies that were not included in the response, either "load more comments"
+// stubs listing the ids of the missing replies or "continue this thread" links to a deeper thread
+func (c Comment) IsMore() bool {
+	return len(c.MoreIds) > 0 || c.MoreUrl != ""
+}
+
+func (c Comment) MoreDescription() string {
+	if len(c.MoreIds) == 0 {
+		return "continue this thread"
+	} else if c.MoreCount == 1 {
+		return "load more comments (1 reply)"
+	}
+
+	return fmt.Sprintf("load more comments (%d replies)", c.MoreCount)
+}
+
+// Turn the comment into a placeholder, keeping its id, depth and timestamp. Comments removed by
+// moderators say so, anything else is shown as deleted
+func (c Comment) AsDeleted() Comment {
+	text := DeletedText
+	if strings.Contains(c.Text, RemovedText) {
+		text = RemovedText
+	}
+
+	c.Author = DeletedAuthor
+	c.Text = text
+	c.Points = ""
+	c.Deleted = true
+	return c
+}
+
+// Comments deleted by their author or removed by moderators keep their replies but lose their author and text
+func IsDeletedComment(author, text string) bool {
+	text = strings.TrimSpace(text)
+	return strings.TrimSpace(author) == DeletedAuthor && (text == DeletedText || text == RemovedText)
+}
+
+// Reddit's name for the comment, used to vote on or save it. Placeholders cannot be voted on
+func (c Comment) Fullname() string {
+	if len(c.Id) == 0 || c.IsMore() || c.Deleted {
+		return ""
+	}
+
+	return "t1_" + c.Id
+}
+
+// Copy of the comment with the vote applied to its points
+func (c Comment) WithVote(vote int) Comment {
+	delta := vote - c.Vote
+	c.Vote = vote
+	c.Score += delta
+
+	// Hidden scores stay hidden
+	if fields := strings.Fields(c.Points); len(fields) == 2 {
+		if points, err := strconv.Atoi(fields[0]); err == nil {
+			c.Points = utils.GetSingularPlural(strconv.Itoa(points+delta), "point", "points")
+		}
+	}
+
+	return c
+}
+
+// Number of actual comments, excluding placeholders for missing replies
+func (c Comments) Count() int {
+	count := 0
+	for _, comment := range c.Comments {
+		if !comment.IsMore() {
+			count++
+		}
+	}
+
+	return count
 }
 
 func (c Comment) Title() string {
@@ -0,0 +1,32 @@
+package model
+
+import "strings"
+
+// Named group of subreddits loaded together as a multireddit
+type Feed struct {
+	Name       string
+	Subreddits []string
+	Sort       PostSort
+}
+
+// Build a feed from user configuration, dropping empty subreddits and unrecognized sorts
+func NewFeed(name string, subreddits []string, sort, timeframe string) Feed {
+	feed := Feed{
+		Name: strings.TrimSpace(name),
+		Sort: NewPostSort(NormalizePostSort(sort), strings.ToLower(strings.TrimSpace(timeframe))),
+	}
+
+	for _, subreddit := range subreddits {
+		subreddit = strings.TrimPrefix(strings.TrimSpace(subreddit), "r/")
+		if len(subreddit) > 0 {
+			feed.Subreddits = append(feed.Subreddits, subreddit)
+		}
+	}
+
+	return feed
+}
+
+// Multireddit path combining every subreddit in the feed, i.e. golang+rust
+func (f Feed) Subreddit() string {
+	return strings.Join(f.Subreddits, "+")
+}
@@ -2,11 +2,17 @@ package model
 
 import (
 	"fmt"
+	"reddittui/utils"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// Author shown by reddit for posts and comments whose account was deleted
+const DeletedAuthor = "[deleted]"
+
 type Post struct {
+	Id            string    `json:"id"`
 	PostTitle     string    `json:"title"`
 	Author        string    `json:"author"`
 	Subreddit     string    `json:"subreddit"`
@@ -16,19 +22,149 @@ type Post struct {
 	CommentsUrl   string    `json:"commentsUrl"`
 	TotalComments string    `json:"totalComments"`
 	TotalLikes    string    `json:"totalLikes"`
+	Score         int       `json:"score"`
+	Created       time.Time `json:"created"`
+	IsComment     bool      `json:"isComment,omitempty"`
+	LinkTitle     string    `json:"linkTitle,omitempty"`
+	Nsfw          bool      `json:"nsfw,omitempty"`
+	Spoiler       bool      `json:"spoiler,omitempty"`
+	Vote          int       `json:"vote,omitempty"`
+	Saved         bool      `json:"saved,omitempty"`
+
+	// Labels shown before the title, set according to the nsfw and spoiler policies
+	Tags []string `json:"-"`
+
+	// Title hidden until the user reveals it, set by the mask policy
+	Masked bool `json:"-"`
+}
+
+// Vote directions, matching the dir parameter of reddit's vote api
+const (
+	Downvote = -1
+	NoVote   = 0
+	Upvote   = 1
+)
+
+// Voting the same way twice takes the vote back
+func ToggleVote(current, vote int) int {
+	if current == vote {
+		return NoVote
+	}
+
+	return vote
+}
+
+// Policies for showing posts marked nsfw or as spoilers. Masked posts are tagged and their titles
+// are hidden until revealed
+const (
+	ShowPosts = "show"
+	TagPosts  = "tag"
+	MaskPosts = "mask"
+	HidePosts = "hide"
+)
+
+// Convert user supplied policies into one of the policies above, defaulting to tagging posts
+func NormalizePostPolicy(policy string) string {
+	switch p := strings.ToLower(strings.TrimSpace(policy)); p {
+	case ShowPosts, MaskPosts, HidePosts:
+		return p
+	default:
+		return TagPosts
+	}
 }
 
+// Kind of listing a page of posts was loaded from, used to route posts to the page displaying them
+type PostsKind int
+
+const (
+	HomePosts PostsKind = iota
+	SubredditPosts
+	SearchPosts
+	UserPosts
+)
+
 type Posts struct {
 	Description string
 	Subreddit   string
 	IsHome      bool
+	Query       string
+	User        string
+	Sort        PostSort
 	Posts       []Post
 	After       string
 	Expiry      time.Time
+	Fetched     time.Time
+
+	// Set when the posts were served from an expired cache entry and should be refreshed
+	Stale bool `json:"-"`
+}
+
+func (p Posts) Kind() PostsKind {
+	if len(p.Query) > 0 {
+		return SearchPosts
+	} else if len(p.User) > 0 {
+		return UserPosts
+	} else if p.IsHome {
+		return HomePosts
+	}
+
+	return SubredditPosts
 }
 
 func (p Post) Title() string {
-	return fmt.Sprintf(" %s  %s", p.TotalLikes, p.PostTitle)
+	var tags strings.Builder
+	for _, tag := range p.Tags {
+		fmt.Fprintf(&tags, "[%s] ", tag)
+	}
+
+	if p.Saved {
+		tags.WriteString("[saved] ")
+	}
+
+	title := p.PostTitle
+	if p.Masked {
+		title = utils.Redact(title)
+	}
+
+	return fmt.Sprintf(" %s%s  %s%s", p.TotalLikes, voteMarker(p.Vote), tags.String(), title)
+}
+
+// Reddit's name for the post, used to vote on, save or hide it. Comments listed on user pages are
+// named as comments
+func (p Post) Fullname() string {
+	if p.IsComment {
+		if len(p.Id) == 0 {
+			return ""
+		}
+
+		return "t1_" + p.Id
+	}
+
+	id := p.Id
+	if len(id) == 0 {
+		// Html listings do not always include the id, but it is part of the comments url
+		id = postIdFromUrl(p.CommentsUrl)
+	}
+
+	if len(id) == 0 {
+		return ""
+	}
+
+	return "t3_" + id
+}
+
+// Copy of the post with the vote applied to its score
+func (p Post) WithVote(vote int) Post {
+	delta := vote - p.Vote
+	p.Vote = vote
+	p.Score += delta
+
+	// Abbreviated scores such as 12.3k do not change with a single vote
+	if likes, err := strconv.Atoi(strings.TrimSpace(p.TotalLikes)); err == nil {
+		p.TotalLikes = strconv.Itoa(likes + delta)
+	}
+
+	return p
 }
 
 func (p Post) Description() string {
@@ -38,7 +174,10 @@ func (p Post) Description() string {
 		sb.WriteString("  ")
 	}
 
-	if strings.TrimSpace(p.TotalComments) == "" {
+	if p.IsComment {
+		// Comments from a user's history are listed along with their posts
+		fmt.Fprintf(&sb, "commented on %s  ", p.LinkTitle)
+	} else if strings.TrimSpace(p.TotalComments) == "" {
 		fmt.Fprintf(&sb, "%d comments  ", 0)
 	} else {
 		fmt.Fprintf(&sb, "%s comments  ", p.TotalComments)
@@ -51,3 +190,31 @@ func (p Post) Description() string {
 func (p Post) FilterValue() string {
 	return p.PostTitle
 }
+
+func postIdFromUrl(commentsUrl string) string {
+	parts := strings.Split(commentsUrl, "/")
+	for i, part := range parts {
+		if part == "comments" && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+
+	return ""
+}
+
+func voteMarker(vote int) string {
+	switch vote {
+	case Upvote:
+		return " ▲"
+	case Downvote:
+		return " ▼"
+	default:
+		return ""
+	}
+}
+
+// Deleted accounts do not have a user page
+func IsDeletedAuthor(author string) bool {
+	author = strings.TrimSpace(author)
+	return len(author) == 0 || author == DeletedAuthor
+}
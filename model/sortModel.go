@@ -0,0 +1,146 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	SortHot           = "hot"
+	SortNew           = "new"
+	SortTop           = "top"
+	SortRising        = "rising"
+	SortControversial = "controversial"
+)
+
+// Additional sorts only available when searching
+const (
+	SortRelevance = "relevance"
+	SortComments  = "comments"
+)
+
+const (
+	TimeframeHour  = "hour"
+	TimeframeDay   = "day"
+	TimeframeWeek  = "week"
+	TimeframeMonth = "month"
+	TimeframeYear  = "year"
+	TimeframeAll   = "all"
+)
+
+type PostSort struct {
+	Sort      string `json:"sort"`
+	Timeframe string `json:"timeframe"`
+}
+
+// Sorting used by reddit when none is specified
+func DefaultPostSort() PostSort {
+	return PostSort{Sort: SortHot}
+}
+
+func NewPostSort(sort, timeframe string) PostSort {
+	postSort := PostSort{Sort: sort}
+	if postSort.HasTimeframe() {
+		postSort.Timeframe = timeframe
+	}
+
+	return postSort
+}
+
+// Sorting used by reddit for search results when none is specified
+func DefaultSearchSort() PostSort {
+	return PostSort{Sort: SortRelevance}
+}
+
+// Sorting used by reddit for user pages when none is specified
+func DefaultUserSort() PostSort {
+	return PostSort{Sort: SortNew}
+}
+
+// Search results can be limited to a time range regardless of the sort
+func NewSearchSort(sort, timeframe string) PostSort {
+	return PostSort{Sort: sort, Timeframe: timeframe}
+}
+
+func (s PostSort) IsDefault() bool {
+	return s.Sort == "" || s.Sort == SortHot
+}
+
+// Only top and controversial posts can be limited to a time range
+func (s PostSort) HasTimeframe() bool {
+	return s.Sort == SortTop || s.Sort == SortControversial
+}
+
+func (s PostSort) String() string {
+	if s.Sort == "" {
+		return SortHot
+	} else if s.Timeframe == "" {
+		return s.Sort
+	}
+
+	return fmt.Sprintf("%s: %s", s.Sort, TimeframeDescription(s.Timeframe))
+}
+
+// Convert user supplied post sorts into the values used in urls, defaulting to hot
+func NormalizePostSort(sort string) string {
+	switch s := strings.ToLower(strings.TrimSpace(sort)); s {
+	case SortHot, SortNew, SortTop, SortRising, SortControversial:
+		return s
+	default:
+		return SortHot
+	}
+}
+
+func TimeframeDescription(timeframe string) string {
+	switch timeframe {
+	case TimeframeHour:
+		return "past hour"
+	case TimeframeDay:
+		return "past 24 hours"
+	case TimeframeWeek:
+		return "past week"
+	case TimeframeMonth:
+		return "past month"
+	case TimeframeYear:
+		return "past year"
+	case TimeframeAll:
+		return "all time"
+	default:
+		return timeframe
+	}
+}
+
+// Comment sorts, using the values expected by the sort query parameter
+const (
+	CommentSortBest          = "confidence"
+	CommentSortTop           = "top"
+	CommentSortNew           = "new"
+	CommentSortControversial = "controversial"
+	CommentSortOld           = "old"
+	CommentSortQA            = "qa"
+)
+
+// Convert user supplied comment sorts such as "best" or "Q&A" into query parameter values
+func NormalizeCommentSort(sort string) string {
+	switch s := strings.ToLower(strings.TrimSpace(sort)); s {
+	case "best", CommentSortBest:
+		return CommentSortBest
+	case "q&a", CommentSortQA:
+		return CommentSortQA
+	case CommentSortTop, CommentSortNew, CommentSortControversial, CommentSortOld:
+		return s
+	default:
+		return ""
+	}
+}
+
+func CommentSortDescription(sort string) string {
+	switch sort {
+	case "", CommentSortBest:
+		return "best"
+	case CommentSortQA:
+		return "Q&A"
+	default:
+		return sort
+	}
+}
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	draftsDirName = "drafts"
+
+	// Everything from this line down is instructions for the user and left out of the draft
+	scissorsLine = "# ------------------------ >8 ------------------------"
+
+	titleHeader = "Title:"
+	urlHeader   = "Url:"
+)
+
+// New post read back from a submission draft. Posts with a url are link posts, others are text posts
+type Submission struct {
+	Title string
+	Url   string
+	Text  string
+}
+
+// Path of the draft with the given name in the state directory, i.e. a reply to a comment's fullname
+func GetDraftPath(name string) (string, error) {
+	stateDir, err := GetStateDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(stateDir, draftsDirName, name+".md"), nil
+}
+
+// Write the template to path unless a draft from an earlier attempt is already there
+func PrepareDraft(path, template string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(template), 0600)
+}
+
+func ReadDraft(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// Forget a draft once it has been posted or cancelled
+func DiscardDraft(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Reply draft starting with the text being replied to, quoted the way reddit quotes text. The text is
+// quoted without the styles it is shown with
+func ReplyTemplate(author, text string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(ansi.Strip(text)), "\n") {
+		if len(strings.TrimSpace(line)) == 0 {
+			sb.WriteString(">\n")
+		} else {
+			fmt.Fprintf(&sb, "> %s\n", line)
+		}
+	}
+
+	sb.WriteString("\n\n")
+	sb.WriteString(scissorsLine + "\n")
+	fmt.Fprintf(&sb, "# Replying to %s. Write your reply above the line, markdown is supported.\n", NormalizeUser(author))
+	sb.WriteString("# Remove the parts of the quote you do not need. Save without a reply to cancel.\n")
+	return sb.String()
+}
+
+// Reply text in a draft, empty when the user did not write anything besides the quote
+func ParseReply(draft string) string {
+	reply := strings.TrimSpace(cutScissors(draft))
+	for _, line := range strings.Split(reply, "\n") {
+		if trimmed := strings.TrimSpace(line); len(trimmed) > 0 && !strings.HasPrefix(trimmed, ">") {
+			return reply
+		}
+	}
+
+	return ""
+}
+
+func SubmissionTemplate(subreddit string) string {
+	var sb strings.Builder
+	sb.WriteString(titleHeader + " \n")
+	sb.WriteString(urlHeader + " \n\n\n")
+	sb.WriteString(scissorsLine + "\n")
+	fmt.Fprintf(&sb, "# Submitting to %s. Set a url for a link post, or leave it empty and write the\n", NormalizeSubreddit(subreddit))
+	sb.WriteString("# text of the post below the headers, markdown is supported. Save without a title to cancel.\n")
+	return sb.String()
+}
+
+// Read the title and url headers and the text of a submission draft
+func ParseSubmission(draft string) Submission {
+	var (
+		submission Submission
+		lines      = strings.Split(cutScissors(draft), "\n")
+		body       = 0
+	)
+
+	for i, line := range lines {
+		if title, ok := cutHeader(line, titleHeader); ok {
+			submission.Title = title
+		} else if url, ok := cutHeader(line, urlHeader); ok {
+			submission.Url = url
+		} else if len(strings.TrimSpace(line)) > 0 {
+			break
+		}
+
+		body = i + 1
+	}
+
+	submission.Text = strings.TrimSpace(strings.Join(lines[body:], "\n"))
+	return submission
+}
+
+// Command that opens path in the user's editor, $VISUAL or $EDITOR, which may include arguments
+func EditorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if len(strings.TrimSpace(editor)) == 0 {
+		editor = os.Getenv("EDITOR")
+	}
+
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+		if runtime.GOOS == "windows" {
+			args = []string{"notepad"}
+		}
+	}
+
+	return exec.Command(args[0], append(args[1:], path)...)
+}
+
+func cutScissors(draft string) string {
+	draft = strings.ReplaceAll(draft, "\r\n", "\n")
+	if before, _, found := strings.Cut(draft, scissorsLine); found {
+		return before
+	}
+
+	return draft
+}
+
+func cutHeader(line, header string) (string, bool) {
+	value, found := strings.CutPrefix(strings.TrimSpace(line), header)
+	if !found {
+		return "", false
+	}
+
+	return strings.TrimSpace(value), true
+}
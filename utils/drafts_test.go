@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseReply(t *testing.T) {
+	template := ReplyTemplate("spez", "First line\n\nSecond line")
+
+	tests := []struct {
+		name  string
+		draft string
+		want  string
+	}{
+		{"untouched template", template, ""},
+		{"empty", "", ""},
+		{"reply below quote", "Great point\n\n" + template, "Great point\n\n> First line\n>\n> Second line"},
+		{"quote removed", "> Second line\n\nAgreed\n" + scissorsLine + "\n# ignored", "> Second line\n\nAgreed"},
+		{"no scissors", "  Just a reply  \r\n", "Just a reply"},
+	}
+
+	for _, tt := range tests {
+		got := ParseReply(tt.draft)
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseSubmission(t *testing.T) {
+	template := SubmissionTemplate("golang")
+
+	tests := []struct {
+		name  string
+		draft string
+		want  Submission
+	}{
+		{"untouched template", template, Submission{}},
+		{"text post", "Title: Hello gophers\nUrl:\n\nFirst paragraph\n\nUrl: not a header\n" + scissorsLine, Submission{"Hello gophers", "", "First paragraph\n\nUrl: not a header"}},
+		{"link post", "Title:  Go 1.24 released \nUrl: https://go.dev/blog\n", Submission{"Go 1.24 released", "https://go.dev/blog", ""}},
+		{"text without headers", "Just some text", Submission{"", "", "Just some text"}},
+	}
+
+	for _, tt := range tests {
+		got := ParseSubmission(tt.draft)
+		if got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPrepareDraft(t *testing.T) {
+	path := filepath.Join(t.TempDir(), draftsDirName, "reply-t1_abc.md")
+
+	if err := PrepareDraft(path, "template"); err != nil {
+		t.Fatalf("unexpected error preparing draft: %v", err)
+	}
+
+	// Drafts kept after a failed submission are not overwritten
+	os.WriteFile(path, []byte("unsent reply"), 0600)
+	if err := PrepareDraft(path, "template"); err != nil {
+		t.Fatalf("unexpected error preparing existing draft: %v", err)
+	}
+
+	if got, _ := ReadDraft(path); got != "unsent reply" {
+		t.Errorf("got %q, want the kept draft", got)
+	}
+
+	if err := DiscardDraft(path); err != nil {
+		t.Errorf("unexpected error discarding draft: %v", err)
+	}
+	if _, err := ReadDraft(path); !os.IsNotExist(err) {
+		t.Errorf("expected draft to be removed, got %v", err)
+	}
+}
@@ -2,6 +2,10 @@ package utils
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 )
 
 func NormalizeSubreddit(subreddit string) string {
@@ -16,6 +20,14 @@ func NormalizeSubreddit(subreddit string) string {
 	return fmt.Sprintf("r/%s", subreddit)
 }
 
+func NormalizeUser(user string) string {
+	if len(user) >= 2 && user[:2] == "u/" {
+		return user
+	}
+
+	return fmt.Sprintf("u/%s", user)
+}
+
 func TruncateString(s string, w int) string {
 	if w <= 0 {
 		return s
@@ -26,6 +38,17 @@ func TruncateString(s string, w int) string {
 	return fmt.Sprintf("%s...", s[:w-3])
 }
 
+// Hide text behind blocks, keeping the spaces between words so it wraps like the text it hides
+func Redact(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return r
+		}
+
+		return '█'
+	}, text)
+}
+
 func Clamp(min, max, val int) int {
 	if val < min {
 		return min
@@ -43,3 +66,51 @@ func GetSingularPlural(s, singular, plural string) string {
 
 	return fmt.Sprintf("%s %s", s, plural)
 }
+
+// Format a score the same way old reddit does, abbreviating large scores with a k suffix
+func FormatScore(score int) string {
+	if score >= 10000 || score <= -10000 {
+		return fmt.Sprintf("%.1fk", float64(score)/1000)
+	}
+
+	return strconv.Itoa(score)
+}
+
+// Format the time elapsed since t in the "5 hours ago" style used by reddit
+func FormatRelativeTime(t time.Time, now time.Time) string {
+	elapsed := now.Sub(t)
+
+	var (
+		amount int
+		unit   string
+	)
+
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		amount, unit = int(elapsed/time.Minute), "minute"
+	case elapsed < 24*time.Hour:
+		amount, unit = int(elapsed/time.Hour), "hour"
+	case elapsed < 30*24*time.Hour:
+		amount, unit = int(elapsed/(24*time.Hour)), "day"
+	case elapsed < 365*24*time.Hour:
+		amount, unit = int(elapsed/(30*24*time.Hour)), "month"
+	default:
+		amount, unit = int(elapsed/(365*24*time.Hour)), "year"
+	}
+
+	return fmt.Sprintf("%s ago", GetSingularPlural(strconv.Itoa(amount), unit, unit+"s"))
+}
+
+// Describe the age of data served from an expired cache entry, empty when the data is fresh
+func CachedDescription(stale bool, fetched time.Time, now time.Time) string {
+	if !stale {
+		return ""
+	} else if fetched.IsZero() {
+		// Entries cached by older versions do not record when they were fetched
+		return "cached"
+	}
+
+	return fmt.Sprintf("cached %s", FormatRelativeTime(fetched, now))
+}
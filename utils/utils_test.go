@@ -1,6 +1,9 @@
 package utils
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestNormalizeSubreddit(t *testing.T) {
 	tests := []struct {
@@ -19,6 +22,23 @@ func TestNormalizeSubreddit(t *testing.T) {
 	}
 }
 
+func TestNormalizeUser(t *testing.T) {
+	tests := []struct {
+		user string
+		want string
+	}{
+		{"spez", "u/spez"},
+		{"u/spez", "u/spez"},
+	}
+
+	for _, tt := range tests {
+		got := NormalizeUser(tt.user)
+		if got != tt.want {
+			t.Errorf("got %s, want %s", got, tt.want)
+		}
+	}
+}
+
 func TestTruncateString(t *testing.T) {
 	tests := []struct {
 		s     string
@@ -40,6 +60,24 @@ func TestTruncateString(t *testing.T) {
 	}
 }
 
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", ""},
+		{"Snape", "█████"},
+		{"Snape kills  Dumbledore", "█████ █████  ██████████"},
+		{"héllo\nwörld", "█████\n█████"},
+	}
+
+	for _, tt := range tests {
+		if got := Redact(tt.text); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
 func TestClamp(t *testing.T) {
 	tests := []struct {
 		min  int
@@ -85,3 +123,69 @@ func TestGetSingularPlural(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatScore(t *testing.T) {
+	tests := []struct {
+		score int
+		want  string
+	}{
+		{0, "0"},
+		{42, "42"},
+		{9999, "9999"},
+		{10000, "10.0k"},
+		{12345, "12.3k"},
+		{-15000, "-15.0k"},
+	}
+
+	for _, tt := range tests {
+		got := FormatScore(tt.score)
+		if got != tt.want {
+			t.Errorf("got %s, want %s with input %d", got, tt.want, tt.score)
+		}
+	}
+}
+
+func TestFormatRelativeTime(t *testing.T) {
+	now := time.Date(2025, time.March, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		elapsed time.Duration
+		want    string
+	}{
+		{30 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{45 * time.Minute, "45 minutes ago"},
+		{5 * time.Hour, "5 hours ago"},
+		{24 * time.Hour, "1 day ago"},
+		{60 * 24 * time.Hour, "2 months ago"},
+		{800 * 24 * time.Hour, "2 years ago"},
+	}
+
+	for _, tt := range tests {
+		got := FormatRelativeTime(now.Add(-tt.elapsed), now)
+		if got != tt.want {
+			t.Errorf("got %s, want %s with elapsed %v", got, tt.want, tt.elapsed)
+		}
+	}
+}
+
+func TestCachedDescription(t *testing.T) {
+	now := time.Date(2025, time.March, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		stale   bool
+		fetched time.Time
+		want    string
+	}{
+		{false, now.Add(-2 * time.Hour), ""},
+		{true, now.Add(-2 * time.Hour), "cached 2 hours ago"},
+		{true, time.Time{}, "cached"},
+	}
+
+	for _, tt := range tests {
+		got := CachedDescription(tt.stale, tt.fetched, now)
+		if got != tt.want {
+			t.Errorf("got %s, want %s with input: stale %v, fetched %v", got, tt.want, tt.stale, tt.fetched)
+		}
+	}
+}